@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// AccessEntry holds the data of an HTTP request to be logged by PrintAccess.
+// Empty string fields are rendered as "-", like web servers do.
+type AccessEntry struct {
+	Host      string    // Remote host, usually the client IP address.
+	Ident     string    // RFC 1413 identity of the client, almost always empty.
+	User      string    // Authenticated user name.
+	Time      time.Time // Time the request was received, the current time is used if zero.
+	Method    string    // e.g. GET
+	URI       string    // Request URI, e.g. /index.html?page=2
+	Proto     string    // e.g. HTTP/1.1
+	Status    int       // Response status code.
+	Bytes     int64     // Size of the response body, zero is rendered as "-".
+	Referer   string    // Referer header, Combined format only.
+	UserAgent string    // User-Agent header, Combined format only.
+}
+
+// clfTimeLayout is the time layout of the Common Log Format, e.g. 10/Oct/2000:13:55:36 -0700.
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+func (l *logger) PrintAccess(level Level, e AccessEntry) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
+	}
+	if e.Time.IsZero() {
+		l.Lock()
+		now := l.now
+		l.Unlock()
+		e.Time = now()
+	}
+	l.handleError(l.printOut(level, nil, func(b *buffer) { *b = appendAccess(*b, &e) }))
+	l.exitIfFatal(level)
+}
+
+func (l *logger) LogRequest(level Level, r *http.Request, status int, dur time.Duration) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
+	}
+	var path string
+	if r.URL != nil {
+		path = r.URL.Path
+	}
+	fields := []Field{
+		String("method", r.Method),
+		String("path", path),
+		Int("status", status),
+		String("duration", dur.String()),
+		String("remote_addr", r.RemoteAddr),
+	}
+	if ua := r.UserAgent(); ua != "" {
+		fields = append(fields, String("user_agent", ua))
+	}
+	l.handleError(l.printOut(level, fields, func(b *buffer) { *b = append(*b, "HTTP request"...) }))
+	l.exitIfFatal(level)
+}
+
+// appendAccess appends e to b in the Common Log Format, or in the Combined Log Format if the referer
+// or the user agent is set.
+func appendAccess(b []byte, e *AccessEntry) []byte {
+	b = appendCLFField(b, e.Host)
+	b = append(b, ' ')
+	b = appendCLFField(b, e.Ident)
+	b = append(b, ' ')
+	b = appendCLFField(b, e.User)
+	b = append(b, " ["...)
+	b = e.Time.AppendFormat(b, clfTimeLayout)
+	b = append(b, "] \""...)
+	b = appendCLFQuoted(b, e.Method)
+	b = append(b, ' ')
+	b = appendCLFQuoted(b, e.URI)
+	b = append(b, ' ')
+	b = appendCLFQuoted(b, e.Proto)
+	b = append(b, "\" "...)
+	b = strconv.AppendInt(b, int64(e.Status), 10)
+	b = append(b, ' ')
+	if e.Bytes > 0 {
+		b = strconv.AppendInt(b, e.Bytes, 10)
+	} else {
+		b = append(b, '-')
+	}
+	if e.Referer == "" && e.UserAgent == "" {
+		return b
+	}
+	b = append(b, " \""...)
+	b = appendCLFQuoted(b, orDash(e.Referer))
+	b = append(b, "\" \""...)
+	b = appendCLFQuoted(b, orDash(e.UserAgent))
+	return append(b, '"')
+}
+
+// appendCLFField appends an unquoted field, which must not contain spaces.
+func appendCLFField(b []byte, s string) []byte {
+	if s == "" {
+		return append(b, '-')
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c <= ' ' || c == '"' || c == 0x7f {
+			b = append(b, '_')
+		} else {
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// appendCLFQuoted appends s escaping the characters that would break a quoted field.
+func appendCLFQuoted(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c < ' ' || c == 0x7f:
+			b = append(b, '\\', 'x', hex[c>>4], hex[c&0xf])
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
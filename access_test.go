@@ -0,0 +1,75 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintAccess(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelInfo, "", buf, 0)
+	at := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+	tests := []struct {
+		entry    AccessEntry
+		expected string
+	}{
+		{
+			AccessEntry{Host: "127.0.0.1", User: "frank", Time: at, Method: "GET", URI: "/apache_pb.gif", Proto: "HTTP/1.0", Status: 200, Bytes: 2326},
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`,
+		},
+		{
+			AccessEntry{Host: "::1", Time: at, Method: "HEAD", URI: `/say?q="hi"`, Proto: "HTTP/1.1", Status: 304},
+			`::1 - - [10/Oct/2000:13:55:36 -0700] "HEAD /say?q=\"hi\" HTTP/1.1" 304 -`,
+		},
+		{
+			AccessEntry{Host: "10.0.0.2", User: "jane doe", Time: at, Method: "POST", URI: "/form", Proto: "HTTP/2.0", Status: 201, Bytes: 12, UserAgent: "curl/8.0\n"},
+			`10.0.0.2 - jane_doe [10/Oct/2000:13:55:36 -0700] "POST /form HTTP/2.0" 201 12 "-" "curl/8.0\x0a"`,
+		},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		l.PrintAccess(LevelInfo, test.entry)
+		out := buf.String()
+		t.Log("Got: ", out)
+		if got := out[13:]; got != test.expected+"\n" {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", test.expected, got)
+		}
+	}
+
+	buf.Reset()
+	l.PrintAccess(LevelDebug, tests[0].entry)
+	if buf.Len() != 0 {
+		t.Errorf("Disabled level was printed: %s", buf.String())
+	}
+}
+
+func TestLogRequest(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelInfo, "", buf, 0)
+	r := httptest.NewRequest("POST", "/login?token=secret", nil)
+	r.RemoteAddr = "10.0.0.2:51234"
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("Cookie", "session=secret")
+	r.Header.Set("User-Agent", "curl/8.0")
+	l.LogRequest(LevelInfo, r, 401, 1500*time.Microsecond)
+	out := buf.String()
+	t.Log("Got: ", out)
+	expected := "HTTP request duration=1.5ms method=POST path=/login remote_addr=10.0.0.2:51234 status=401 user_agent=curl/8.0\n"
+	if got := out[13:]; got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, got)
+	}
+	if strings.Contains(out, "secret") {
+		t.Errorf("Sensitive data was logged: %s", out)
+	}
+
+	buf.Reset()
+	l.LogRequest(LevelDebug, r, 200, time.Millisecond)
+	if buf.Len() != 0 {
+		t.Errorf("Disabled level was written: %q", buf.String())
+	}
+}
@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+func (l *logger) PrintBanner(level Level) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
+	}
+	fields := []Field{
+		String("level", levelName(l.GetLevel())),
+		String("flags", l.FlagsString()),
+		String("hostname", hostname()),
+		Int("pid", os.Getpid()),
+	}
+	l.handleError(l.printOut(level, fields, func(b *buffer) { *b = append(*b, "Logger started"...) }))
+	l.exitIfFatal(level)
+}
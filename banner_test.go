@@ -0,0 +1,42 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPrintBanner(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelInfo, "", buf, FlagSequence)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	l.SetVersion("1.4.2")
+	entries := l.Capture(func() { l.PrintBanner(LevelInfo) })
+	if len(entries) != 1 || entries[0].Message != "Logger started" {
+		t.Fatalf("Pattern mismatch,\n\texpected: a Logger started entry\n\tgot: %+v", entries)
+	}
+	expected := map[string]any{
+		"level":    "info",
+		"flags":    "sequence",
+		"hostname": hostname(),
+		"pid":      int64(os.Getpid()),
+		"version":  "1.4.2",
+	}
+	got := make(map[string]any)
+	for _, f := range entries[0].Fields {
+		got[f.Key] = f.Value()
+	}
+	for key, value := range expected {
+		if got[key] != value {
+			t.Errorf("Pattern mismatch for %s,\n\texpected: %v\n\tgot: %v", key, value, got[key])
+		}
+	}
+
+	l.PrintBanner(LevelDebug)
+	if buf.Len() != 0 {
+		t.Errorf("Disabled banner was written: %q", buf.String())
+	}
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// BatchWriter is an io.Writer gathering writes in memory and passing them to an underlying writer at once,
+// so that many entries cost a single system call. The batch is written once it reaches a size, every interval,
+// and by Flush, which ILogger.Flush calls. Writes are kept in order and are never split across batches.
+// A failed batch is dropped and its error returned by the next Write or Flush.
+type BatchWriter struct {
+	out  io.Writer
+	size int
+	buf  []byte
+	err  error
+	// owned tells whether out was opened by NewBatchFileWriter, so that Close closes it.
+	owned  bool
+	closed bool
+	stop   chan struct{}
+	done   chan struct{}
+	sync.Mutex
+}
+
+// NewBatchWriter returns a BatchWriter writing to out once size bytes are gathered, and every interval
+// if it is positive. Close it when done to write the last batch and stop the background goroutine.
+func NewBatchWriter(out io.Writer, size int, interval time.Duration) *BatchWriter {
+	w := &BatchWriter{out: out, size: size, buf: make([]byte, 0, size), stop: make(chan struct{}), done: make(chan struct{})}
+	if interval > 0 {
+		go w.run(interval)
+	} else {
+		close(w.done)
+	}
+	return w
+}
+
+// NewBatchFileWriter opens (or creates) the file at path in append mode and returns a BatchWriter writing to it,
+// like NewBatchWriter does. Close closes the file too.
+func NewBatchFileWriter(path string, size int, interval time.Duration) (*BatchWriter, error) {
+	f, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w := NewBatchWriter(f, size, interval)
+	w.owned = true
+	return w, nil
+}
+
+func (w *BatchWriter) run(interval time.Duration) {
+	defer close(w.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.Lock()
+			if err := w.flush(); err != nil {
+				w.err = err
+			}
+			w.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Write adds a copy of p to the batch, writing the batch first if p does not fit in it.
+func (w *BatchWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if err := w.err; err != nil {
+		w.err = nil
+		return 0, err
+	}
+	if len(w.buf) > 0 && len(w.buf)+len(p) > w.size {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.size {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes the batch, returning the error of a failed batch if any.
+func (w *BatchWriter) Flush() error {
+	w.Lock()
+	defer w.Unlock()
+	err := w.flush()
+	if w.err != nil {
+		err, w.err = w.err, nil
+	}
+	return err
+}
+
+// flush writes the gathered writes at once. It must be called with the lock held.
+func (w *BatchWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.out.Write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+// Close writes the last batch and stops the background goroutine, subsequent writes fail with os.ErrClosed.
+func (w *BatchWriter) Close() error {
+	w.Lock()
+	if w.closed {
+		w.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.stop)
+	w.Unlock()
+	<-w.done
+	err := w.Flush()
+	if c, ok := w.out.(io.Closer); ok && w.owned {
+		if e := c.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingWriter counts the writes it receives.
+type countingWriter struct {
+	buf    bytes.Buffer
+	writes int
+	sync.Mutex
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	w.writes++
+	return w.buf.Write(p)
+}
+
+func (w *countingWriter) String() string {
+	w.Lock()
+	defer w.Unlock()
+	return w.buf.String()
+}
+
+func TestBatchWriter(t *testing.T) {
+	out := new(countingWriter)
+	// Each entry is 21 bytes long, so a batch holds 4 of them.
+	w := NewBatchWriter(out, 100, 0)
+	l := New(LevelTrace, "", w, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	var expected string
+	for i := 0; i < 10; i++ {
+		l.Printf(LevelInfo, "entry %d", i)
+		expected += fmt.Sprintf("I/10:00:00 : entry %d\n", i)
+	}
+	if out.writes != 2 || out.String() != expected[:8*21] {
+		t.Errorf("Pattern mismatch after %d writes,\n\texpected: %q\n\tgot: %q", out.writes, expected[:8*21], out.String())
+	}
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if out.writes != 3 || out.String() != expected {
+		t.Errorf("Pattern mismatch after %d writes,\n\texpected: %q\n\tgot: %q", out.writes, expected, out.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("closed")); err != os.ErrClosed {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", os.ErrClosed, err)
+	}
+}
+
+func TestBatchWriterInterval(t *testing.T) {
+	out := new(countingWriter)
+	w := NewBatchWriter(out, 4096, 10*time.Millisecond)
+	defer w.Close()
+	_, _ = w.Write([]byte("first\n"))
+	_, _ = w.Write([]byte("second\n"))
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := out.String(); got != "first\nsecond\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "first\nsecond\n", got)
+	}
+}
+
+func TestBatchFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch.log")
+	w, err := NewBatchFileWriter(path, 4096, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("entry\n"))
+	if got := readFile(t, path); got != "" {
+		t.Errorf("Batch was written before Close: %q", got)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "entry\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "entry\n", got)
+	}
+}
+
+func BenchmarkFileWrites(b *testing.B) {
+	line := []byte("I/10:00:00 : Benchmark entry 42\n")
+	b.Run("PerLine", func(b *testing.B) {
+		f, err := openLogFile(filepath.Join(b.TempDir(), "lines.log"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer f.Close()
+		for i := 0; i < b.N; i++ {
+			_, _ = f.Write(line)
+		}
+	})
+	b.Run("Batched", func(b *testing.B) {
+		w, err := NewBatchFileWriter(filepath.Join(b.TempDir(), "batch.log"), 64*1024, time.Second)
+		if err != nil {
+			b.Fatal(err)
+		}
+		defer w.Close()
+		for i := 0; i < b.N; i++ {
+			_, _ = w.Write(line)
+		}
+	})
+}
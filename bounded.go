@@ -0,0 +1,60 @@
+package logger
+
+import "sync"
+
+// OverflowPolicy tells which entries are discarded when a bounded writer is full.
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest retained writes to make room for new ones.
+	DropOldest OverflowPolicy = iota
+	// DropNewest keeps the retained writes and discards the new ones which do not fit.
+	DropNewest
+)
+
+// BoundedBufferWriter is an io.Writer keeping at most a fixed number of bytes in memory.
+// Writes are retained or dropped as a whole, so an entry is never cut in half. A write larger than
+// the cap is always dropped. It suits embedded deployments that must not grow their memory usage.
+type BoundedBufferWriter struct {
+	max    int
+	policy OverflowPolicy
+	data   []byte
+	sizes  []int
+	sync.Mutex
+}
+
+// NewBoundedBufferWriter returns a BoundedBufferWriter retaining at most maxBytes bytes, using policy when full.
+func NewBoundedBufferWriter(maxBytes int, policy OverflowPolicy) *BoundedBufferWriter {
+	return &BoundedBufferWriter{max: maxBytes, policy: policy}
+}
+
+// Write retains a copy of p, discarding content according to the overflow policy. It never fails.
+func (w *BoundedBufferWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if len(p) > w.max {
+		return len(p), nil
+	}
+	if len(w.data)+len(p) > w.max {
+		if w.policy == DropNewest {
+			return len(p), nil
+		}
+		drop, n := 0, 0
+		for len(w.data)-drop+len(p) > w.max {
+			drop += w.sizes[n]
+			n++
+		}
+		w.data = w.data[:copy(w.data, w.data[drop:])]
+		w.sizes = w.sizes[:copy(w.sizes, w.sizes[n:])]
+	}
+	w.data = append(w.data, p...)
+	w.sizes = append(w.sizes, len(p))
+	return len(p), nil
+}
+
+// Bytes returns a copy of the retained content.
+func (w *BoundedBufferWriter) Bytes() []byte {
+	w.Lock()
+	defer w.Unlock()
+	return append([]byte(nil), w.data...)
+}
@@ -0,0 +1,36 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBoundedBufferWriter(t *testing.T) {
+	tests := []struct {
+		policy   OverflowPolicy
+		expected string
+	}{
+		{DropOldest, "I/10:00:00 : entry 7\nI/10:00:00 : entry 8\nI/10:00:00 : entry 9\n"},
+		{DropNewest, "I/10:00:00 : entry 0\nI/10:00:00 : entry 1\nI/10:00:00 : entry 2\n"},
+	}
+	for _, test := range tests {
+		// Each entry is 21 bytes long, so 3 of them fit in the cap.
+		w := NewBoundedBufferWriter(64, test.policy)
+		l := New(LevelTrace, "", w, 0)
+		l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+		for i := 0; i < 10; i++ {
+			l.Printf(LevelInfo, "entry %d", i)
+			if n := len(w.Bytes()); n > 64 {
+				t.Fatalf("Cap exceeded with policy %d: %d bytes", test.policy, n)
+			}
+		}
+		// Entries larger than the cap are always dropped.
+		l.Println(LevelInfo, strings.Repeat("x", 64))
+		if got := string(w.Bytes()); got != test.expected {
+			t.Errorf("Pattern mismatch with policy %d,\n\texpected: %q\n\tgot: %q", test.policy, test.expected, got)
+		}
+	}
+}
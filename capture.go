@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+)
+
+// captureSink is the output of a logger during Capture, keeping a copy of every entry.
+type captureSink struct {
+	entries []Entry
+	sync.Mutex
+}
+
+func (s *captureSink) WriteEntry(e *Entry) error {
+	s.Lock()
+	defer s.Unlock()
+	c := *e
+	c.Fields = append([]Field(nil), e.Fields...)
+	s.entries = append(s.entries, c)
+	return nil
+}
+
+// Write keeps p as the message of a LevelInfo entry, for lines written to the output without being formatted.
+func (s *captureSink) Write(p []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	s.entries = append(s.entries, Entry{Level: LevelInfo, Message: string(bytes.TrimSuffix(p, []byte{'\n'}))})
+	return len(p), nil
+}
+
+func (l *logger) Capture(fn func()) []Entry {
+	sink := new(captureSink)
+	l.Lock()
+	out, terminal, levelOut := l.out, l.terminal, l.levelOut
+	l.out, l.terminal, l.levelOut = sink, false, nil
+	l.resetColors()
+	l.Unlock()
+	defer func() {
+		l.Lock()
+		l.out, l.terminal, l.levelOut = out, terminal, levelOut
+		l.resetColors()
+		l.Unlock()
+	}()
+	fn()
+	sink.Lock()
+	defer sink.Unlock()
+	return sink.entries
+}
@@ -0,0 +1,46 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCapture(t *testing.T) {
+	buf, errBuf := new(bytes.Buffer), new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagColorMode)
+	l.SetLevelOutput(LevelError, errBuf)
+	entries := l.Capture(func() {
+		l.Println(LevelInfo, "Captured")
+		l.PrintFields(LevelError, "Failed", Int("code", 7))
+	})
+	if buf.Len() != 0 || errBuf.Len() != 0 {
+		t.Errorf("Captured entries were written: %q %q", buf.String(), errBuf.String())
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Pattern mismatch,\n\texpected: 2 entries\n\tgot: %+v", entries)
+	}
+	if e := entries[0]; e.Level != LevelInfo || e.Message != "Captured" {
+		t.Errorf("Pattern mismatch,\n\texpected: Captured at LevelInfo\n\tgot: %+v", e)
+	}
+	if e := entries[1]; e.Level != LevelError || e.Message != "Failed" || len(e.Fields) != 1 || e.Fields[0].Value() != int64(7) {
+		t.Errorf("Pattern mismatch,\n\texpected: Failed at LevelError with code=7\n\tgot: %+v", e)
+	}
+
+	l.Println(LevelInfo, "Restored")
+	l.Println(LevelError, "Restored")
+	if !bytes.Contains(buf.Bytes(), []byte("Restored")) || !bytes.Contains(errBuf.Bytes(), []byte("Restored")) {
+		t.Errorf("Outputs were not restored: %q %q", buf.String(), errBuf.String())
+	}
+
+	buf.Reset()
+	func() {
+		defer func() { _ = recover() }()
+		l.Capture(func() { panic("boom") })
+	}()
+	l.Println(LevelInfo, "After panic")
+	if !bytes.Contains(buf.Bytes(), []byte("After panic")) {
+		t.Errorf("Output was not restored after a panic: %q", buf.String())
+	}
+}
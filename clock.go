@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// coarseClock is a low resolution clock updated by a background ticker.
+// Reading it is cheaper than calling time.Now, at the cost of precision.
+type coarseClock struct {
+	t          atomic.Value
+	resolution time.Duration
+	stop       chan struct{}
+}
+
+func newCoarseClock(resolution time.Duration) *coarseClock {
+	c := &coarseClock{resolution: resolution, stop: make(chan struct{})}
+	c.t.Store(time.Now())
+	ticker := time.NewTicker(resolution)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case t := <-ticker.C:
+				c.t.Store(t)
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+func (c *coarseClock) now() time.Time {
+	return c.t.Load().(time.Time)
+}
+
+func (c *coarseClock) close() {
+	close(c.stop)
+}
+
+func (l *logger) SetTimeFunc(now func() time.Time) {
+	l.Lock()
+	defer l.Unlock()
+	l.stopCoarseClock()
+	if now == nil {
+		now = time.Now
+	}
+	l.now = now
+}
+
+func (l *logger) SetCoarseTime(resolution time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	l.stopCoarseClock()
+	l.now = time.Now
+	if resolution > 0 {
+		l.coarse = newCoarseClock(resolution)
+		l.now = l.coarse.now
+	}
+}
+
+// stopCoarseClock stops the background ticker of the coarse clock if one is running.
+// It must be called with the lock held.
+func (l *logger) stopCoarseClock() {
+	if l.coarse != nil {
+		l.coarse.close()
+		l.coarse = nil
+	}
+}
@@ -0,0 +1,79 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeFunc(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 2, 13, 4, 5, 0, time.Local) })
+	l.Println(LevelInfo, "Fixed time")
+	out := buf.String()
+	expected := "I/13:04:05 : Fixed time\n"
+	t.Log("Got: ", out)
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+	l.SetTimeFunc(nil)
+	l.Println(LevelInfo, "Real time")
+}
+
+func TestCoarseTime(t *testing.T) {
+	c := newCoarseClock(20 * time.Millisecond)
+	defer c.close()
+	t1, t2 := c.now(), c.now()
+	if !t1.Equal(t2) {
+		t.Errorf("Coarse clock changed between two immediate reads: %v, %v", t1, t2)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if t3 := c.now(); t3.Sub(t1) < 20*time.Millisecond {
+		t.Errorf("Coarse clock did not advance at its resolution: %v, %v", t1, t3)
+	}
+
+	l := New(LevelTrace, "", io.Discard, 0)
+	l.SetCoarseTime(10 * time.Millisecond)
+	l.Println(LevelInfo, "Coarse entry")
+	l.Clone().SetCoarseTime(0)
+	l.SetCoarseTime(0)
+}
+
+// BenchmarkCoarseTime is to be compared with BenchmarkPrintln, which reads the real clock.
+func BenchmarkCoarseTime(b *testing.B) {
+	l := New(LevelTrace, "BENCH", io.Discard, 0)
+	l.SetCoarseTime(100 * time.Millisecond)
+	defer l.SetCoarseTime(0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Println(LevelInfo, "Benchmark entry", 42)
+	}
+}
+
+func TestDeltaTime(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagDeltaTime)
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	l.SetTimeFunc(func() time.Time { return now })
+	for _, gap := range []time.Duration{0, 5 * time.Millisecond, 1500 * time.Millisecond, 250 * time.Microsecond} {
+		now = now.Add(gap)
+		l.Println(LevelInfo, "Step")
+	}
+	// Replayed entries may go back in time.
+	l.PrintAt(now.Add(-time.Second), LevelInfo, "Replayed")
+	expected := "+0s Step\n+5ms Step\n+1.5s Step\n+250µs Step\n-1s Replayed\n"
+	var got string
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if len(line) > 11 {
+			got += strings.Replace(line[11:], " : ", " ", 1)
+		}
+	}
+	if got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+}
@@ -0,0 +1,307 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+// These follow the https://no-color.org and FORCE_COLOR conventions, they are read once at start up.
+var (
+	envNoColor    = os.Getenv("NO_COLOR") != ""
+	envForceColor = os.Getenv("FORCE_COLOR") != "" && os.Getenv("FORCE_COLOR") != "0"
+)
+
+// stdoutTerminal tells whether the standard output is a terminal, that is whether the program runs interactively
+// rather than in a pipeline. It is checked once at start up.
+var stdoutTerminal = isTerminal(os.Stdout)
+
+// isTerminal tells whether out is a character device like a terminal.
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorEnabled is the color decision for given flags, output kind, standard output kind and environment:
+// NO_COLOR always wins, FlagColorMode always colors, FlagAutoColor colors only when both the output and
+// the standard output are terminals, unless FORCE_COLOR is set.
+func colorEnabled(flags int, terminal, interactive, noColor, forceColor bool) bool {
+	switch {
+	case noColor:
+		return false
+	case flags&FlagColorMode != 0:
+		return true
+	case flags&FlagAutoColor != 0:
+		return terminal && interactive || forceColor
+	}
+	return false
+}
+
+// colorCache holds the escape sequence starting the colored entries of every level, nil for levels which are
+// not colored, so that entries are colored without map lookups. It is prepared on first use.
+type colorCache struct {
+	seqs  [LevelTrace + 1][]byte
+	ready bool
+}
+
+// resetColors drops the cached colors, it must be called by setters changing the flags, the output or the colors.
+// It must be called with the lock held.
+func (l *logger) resetColors() {
+	l.colorCache = colorCache{}
+}
+
+// cachedColor returns the escape sequence starting colored entries of level, or nil if they are not to be
+// colorized. It must be called with the lock held.
+func (l *logger) cachedColor(level Level) []byte {
+	if level <= LevelQuiet || level > LevelTrace {
+		return nil
+	}
+	if !l.colorCache.ready {
+		enabled := colorEnabled(l.flags, l.terminal, stdoutTerminal, envNoColor, envForceColor)
+		for lv := LevelFatal; lv <= LevelTrace; lv++ {
+			l.colorCache.seqs[lv] = nil
+			if enabled {
+				l.colorCache.seqs[lv] = l.levelColor(lv)
+			}
+		}
+		l.colorCache.ready = true
+	}
+	return l.colorCache.seqs[level]
+}
+
+// StripColor returns a copy of b without ANSI SGR escape sequences like "\033[31;1m", as used by FlagColorMode.
+// It is useful to compare colored output in tests or to post-process logs.
+func StripColor(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] == '\033' && i+1 < len(b) && b[i+1] == '[' {
+			j := i + 2
+			for j < len(b) && (b[j] >= '0' && b[j] <= '9' || b[j] == ';') {
+				j++
+			}
+			if j < len(b) && b[j] == 'm' {
+				i = j
+				continue
+			}
+		}
+		out = append(out, b[i])
+	}
+	return out
+}
+
+// SGR is a Select Graphic Rendition parameter, a text style composed with level colors by ILogger.SetLevelStyle.
+type SGR int
+
+// Styles supported by most terminals, Italic is not rendered everywhere.
+const (
+	Bold      SGR = 1
+	Italic    SGR = 3
+	Underline SGR = 4
+	Reverse   SGR = 7
+)
+
+// composeStyle returns the escape sequence of color, like "\033[31m", with styles appended to its parameters.
+// If color is not such a sequence, the styles are returned on their own, like "\033[1;4m".
+func composeStyle(color []byte, styles []SGR) []byte {
+	seq := append([]byte(nil), "\033["...)
+	if isSGR(color) && len(color) > len("\033[m") {
+		seq = append(seq[:0], color[:len(color)-1]...)
+		seq = append(seq, ';')
+	}
+	for i, s := range styles {
+		if i > 0 {
+			seq = append(seq, ';')
+		}
+		seq = strconv.AppendInt(seq, int64(s), 10)
+	}
+	return append(seq, 'm')
+}
+
+// isSGR tells whether b is a single SGR escape sequence, like "\033[31;1m".
+func isSGR(b []byte) bool {
+	if len(b) < len("\033[m") || b[0] != '\033' || b[1] != '[' || b[len(b)-1] != 'm' {
+		return false
+	}
+	for _, c := range b[2 : len(b)-1] {
+		if (c < '0' || c > '9') && c != ';' {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *logger) SetLevelStyle(level Level, styles ...SGR) {
+	if _, ok := levelColors[level]; !ok {
+		return
+	}
+	l.Lock()
+	defer l.Unlock()
+	l.resetColors()
+	if len(styles) == 0 {
+		delete(l.styles, level)
+		return
+	}
+	if l.styles == nil {
+		l.styles = make(map[Level]levelStyle)
+	}
+	l.styles[level] = levelStyle{seq: composeStyle(l.baseColor(level), styles), sgr: append([]SGR(nil), styles...)}
+}
+
+// levelStyle is a level color composed with styles, sgr is kept to copy it.
+type levelStyle struct {
+	seq []byte
+	sgr []SGR
+}
+
+// levelColor returns the escape sequence starting colored entries of level. It must be called with the lock held.
+func (l *logger) levelColor(level Level) []byte {
+	if style, ok := l.styles[level]; ok {
+		return style.seq
+	}
+	return l.baseColor(level)
+}
+
+// baseColor returns the color of level without styles, from the theme if one is set.
+// It must be called with the lock held.
+func (l *logger) baseColor(level Level) []byte {
+	if color, ok := l.colors[level]; ok {
+		return color
+	}
+	return levelColors[level]
+}
+
+// ColorRGB returns the escape sequence of a 24-bit foreground color, like "\033[38;2;220;50;47m".
+// It is rendered by most modern terminals, others may ignore it or pick the closest color.
+func ColorRGB(r, g, b uint8) []byte {
+	seq := append([]byte(nil), "\033[38;2;"...)
+	seq = strconv.AppendInt(seq, int64(r), 10)
+	seq = append(seq, ';')
+	seq = strconv.AppendInt(seq, int64(g), 10)
+	seq = append(seq, ';')
+	seq = strconv.AppendInt(seq, int64(b), 10)
+	return append(seq, 'm')
+}
+
+// Theme is the name of a set of level colors, see ILogger.SetTheme.
+type Theme string
+
+// Themes shipped with the package. ThemeBasic uses the 16 colors supported by every terminal,
+// the other ones use 24-bit colors from the palettes they are named after.
+const (
+	ThemeBasic     Theme = "basic"
+	ThemeSolarized Theme = "solarized"
+	ThemeMonokai   Theme = "monokai"
+)
+
+// themes holds the colors of every Theme but ThemeBasic, which is levelColors.
+var themes = map[Theme]map[Level][]byte{
+	ThemeSolarized: {
+		LevelFatal: ColorRGB(0xd3, 0x36, 0x82),
+		LevelError: ColorRGB(0xdc, 0x32, 0x2f),
+		LevelWarn:  ColorRGB(0xb5, 0x89, 0x00),
+		LevelInfo:  ColorRGB(0x85, 0x99, 0x00),
+		LevelDebug: ColorRGB(0x26, 0x8b, 0xd2),
+		LevelTrace: ColorRGB(0x2a, 0xa1, 0x98),
+	},
+	ThemeMonokai: {
+		LevelFatal: ColorRGB(0xf9, 0x26, 0x72),
+		LevelError: ColorRGB(0xfd, 0x97, 0x1f),
+		LevelWarn:  ColorRGB(0xe6, 0xdb, 0x74),
+		LevelInfo:  ColorRGB(0xa6, 0xe2, 0x2e),
+		LevelDebug: ColorRGB(0x66, 0xd9, 0xef),
+		LevelTrace: ColorRGB(0x75, 0x71, 0x5e),
+	},
+}
+
+func (l *logger) SetTheme(theme Theme) error {
+	colors, ok := themes[theme]
+	if !ok && theme != ThemeBasic {
+		return errors.New("logger: unknown theme " + string(theme))
+	}
+	l.Lock()
+	defer l.Unlock()
+	l.setColors(colors)
+	return nil
+}
+
+func (l *logger) SetColorScheme(scheme map[Level][]byte) error {
+	colors := make(map[Level][]byte, len(scheme))
+	for level, color := range scheme {
+		if _, ok := levelColors[level]; !ok {
+			return errors.New("logger: unknown level " + strconv.Itoa(int(level)))
+		}
+		if !isSGR(color) {
+			return errors.New("logger: invalid color " + strconv.Quote(string(color)) + " for level " + strconv.Itoa(int(level)))
+		}
+		colors[level] = append([]byte(nil), color...)
+	}
+	l.Lock()
+	defer l.Unlock()
+	l.setColors(colors)
+	return nil
+}
+
+func (l *logger) SetLevelColor(level Level, color []byte) {
+	if _, ok := levelColors[level]; !ok || color != nil && !isSGR(color) {
+		return
+	}
+	l.Lock()
+	defer l.Unlock()
+	// Copy, the colors may be those of a theme or shared with a clone.
+	colors := make(map[Level][]byte, len(l.colors)+1)
+	for k, v := range l.colors {
+		colors[k] = v
+	}
+	if color == nil {
+		delete(colors, level)
+	} else {
+		colors[level] = append([]byte(nil), color...)
+	}
+	l.setColors(colors)
+}
+
+// setColors replaces the level colors, composing the styles set by SetLevelStyle again.
+// It must be called with the lock held.
+func (l *logger) setColors(colors map[Level][]byte) {
+	l.colors = colors
+	l.resetColors()
+	for level, style := range l.styles {
+		l.styles[level] = levelStyle{seq: composeStyle(l.baseColor(level), style.sgr), sgr: style.sgr}
+	}
+}
+
+// ColorRule returns the escape sequence to colorize an entry with, e.g. "\033[31m", and true if it applies
+// to the fields of the entry. Values are those returned by Field.Value, e.g. int64 for Int fields.
+// See ILogger.AddColorRule.
+type ColorRule func(fields map[string]any) ([]byte, bool)
+
+func (l *logger) AddColorRule(rule ColorRule) {
+	l.Lock()
+	defer l.Unlock()
+	// Always copy, like hooks, so Clone never shares the backing array.
+	l.colorRules = append(l.colorRules[:len(l.colorRules):len(l.colorRules)], rule)
+}
+
+// entryColor returns the escape sequence starting an entry of level with fields: the one of the first
+// matching color rule, or the level color. It returns nil if the entry is not to be colorized.
+// It must be called with the lock held.
+func (l *logger) entryColor(level Level, fields []Field) []byte {
+	color := l.cachedColor(level)
+	if color != nil && len(l.colorRules) > 0 {
+		values := make(map[string]any, len(fields))
+		for _, f := range fields {
+			values[f.Key] = f.Value()
+		}
+		for _, rule := range l.colorRules {
+			if c, ok := rule(values); ok {
+				return c
+			}
+		}
+	}
+	return color
+}
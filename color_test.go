@@ -0,0 +1,287 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColorDecision(t *testing.T) {
+	tests := []struct {
+		flags                                      int
+		terminal, interactive, noColor, forceColor bool
+		expected                                   bool
+	}{
+		{0, false, false, false, false, false},
+		{0, true, true, false, true, false},
+		{FlagColorMode, false, false, false, false, true},
+		{FlagColorMode, true, true, false, false, true},
+		{FlagColorMode, true, true, true, false, false},
+		{FlagColorMode, false, false, true, true, false},
+		{FlagAutoColor, false, false, false, false, false},
+		{FlagAutoColor, true, true, false, false, true},
+		{FlagAutoColor, true, false, false, false, false},
+		{FlagAutoColor, false, true, false, false, false},
+		{FlagAutoColor, false, false, false, true, true},
+		{FlagAutoColor, true, true, true, true, false},
+		{FlagAutoColor | FlagColorMode, false, false, false, false, true},
+	}
+	for _, test := range tests {
+		got := colorEnabled(test.flags, test.terminal, test.interactive, test.noColor, test.forceColor)
+		if got != test.expected {
+			t.Errorf("Pattern mismatch for %+v,\n\texpected: %v\n\tgot: %v", test, test.expected, got)
+		}
+	}
+}
+
+func TestAutoColor(t *testing.T) {
+	if envNoColor || envForceColor {
+		t.Skip("NO_COLOR or FORCE_COLOR is set")
+	}
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagAutoColor)
+	l.Println(LevelError, "Not a terminal")
+	if out := buf.String(); out[:2] != "E/" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "E/", out)
+	}
+	buf.Reset()
+	l.SetFlags(FlagColorMode)
+	l.Println(LevelError, "Forced")
+	if out := buf.String(); out[:len(levelColors[LevelError])] != string(levelColors[LevelError]) {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", levelColors[LevelError], out)
+	}
+}
+
+func TestStripColor(t *testing.T) {
+	colored, plain := new(bytes.Buffer), new(bytes.Buffer)
+	now := func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) }
+	for out, flags := range map[*bytes.Buffer]int{colored: FlagColorMode, plain: 0} {
+		l := New(LevelTrace, "STRIP", out, flags)
+		l.SetTimeFunc(now)
+		for level := LevelError; level <= LevelTrace; level++ {
+			l.Println(level, "Entry [31m not an escape")
+		}
+	}
+	if !envNoColor && bytes.Equal(colored.Bytes(), plain.Bytes()) {
+		t.Fatalf("Output was not colored")
+	}
+	got := StripColor(colored.Bytes())
+	if !bytes.Equal(got, plain.Bytes()) {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", plain.Bytes(), got)
+	}
+	if got = StripColor([]byte("\033[1;31mbold red\033[0m \033[")); string(got) != "bold red \033[" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "bold red \033[", got)
+	}
+}
+
+func TestLevelStyle(t *testing.T) {
+	if envNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagColorMode)
+	l.SetLevelStyle(LevelError, Bold, Underline)
+	l.SetLevelStyle(LevelQuiet, Bold)
+	tests := []struct {
+		l        ILogger
+		level    Level
+		expected string
+	}{
+		{l, LevelError, "\033[31;1;4m"},
+		{l.Clone(), LevelError, "\033[31;1;4m"},
+		{l, LevelWarn, "\033[33;1m"},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		test.l.Println(test.level, "Styled")
+		if out := buf.String(); !strings.HasPrefix(out, test.expected) {
+			t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", test.expected, out)
+		}
+	}
+
+	l.SetLevelStyle(LevelError)
+	buf.Reset()
+	l.Println(LevelError, "Plain color")
+	if out := buf.String(); !strings.HasPrefix(out, "\033[31m") {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "\033[31m", out)
+	}
+}
+
+func TestColorRule(t *testing.T) {
+	if envNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagColorMode)
+	red := []byte("\033[31;1m")
+	l.AddColorRule(func(fields map[string]any) ([]byte, bool) {
+		status, ok := fields["status"].(int64)
+		return red, ok && status >= 500
+	})
+	l.AddColorRule(func(fields map[string]any) ([]byte, bool) {
+		return []byte("\033[35m"), true
+	})
+
+	l.PrintFields(LevelInfo, "Request", Int("status", 503))
+	if out := buf.String(); !strings.HasPrefix(out, string(red)) {
+		t.Errorf("Pattern mismatch,\n\texpected prefix: %q\n\tgot: %q", red, out)
+	}
+	buf.Reset()
+	l.PrintFields(LevelInfo, "Request", Int("status", 200))
+	if out := buf.String(); !strings.HasPrefix(out, "\033[35m") {
+		t.Errorf("Pattern mismatch,\n\texpected prefix: %q\n\tgot: %q", "\033[35m", out)
+	}
+
+	// Without rules, the level color is used.
+	buf.Reset()
+	other := New(LevelTrace, "", buf, FlagColorMode)
+	other.PrintFields(LevelInfo, "Request", Int("status", 503))
+	if out := buf.String(); !strings.HasPrefix(out, string(levelColors[LevelInfo])) {
+		t.Errorf("Pattern mismatch,\n\texpected prefix: %q\n\tgot: %q", levelColors[LevelInfo], out)
+	}
+}
+
+func TestTheme(t *testing.T) {
+	if envNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagColorMode)
+	l.SetLevelStyle(LevelWarn, Bold)
+	if err := l.SetTheme("unknown"); err == nil {
+		t.Error("Unknown theme was accepted")
+	}
+	if err := l.SetTheme(ThemeSolarized); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		level    Level
+		expected string
+	}{
+		{LevelError, "\033[38;2;220;50;47m"},
+		{LevelWarn, "\033[38;2;181;137;0;1m"},
+		{LevelInfo, "\033[38;2;133;153;0m"},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		l.Println(test.level, "Themed")
+		if out := buf.String(); !strings.HasPrefix(out, test.expected) {
+			t.Errorf("Pattern mismatch,\n\texpected prefix: %q\n\tgot: %q", test.expected, out)
+		}
+	}
+
+	buf.Reset()
+	_ = l.SetTheme(ThemeMonokai)
+	l.Clone().Println(LevelDebug, "Cloned")
+	if out, expected := buf.String(), "\033[38;2;102;217;239m"; !strings.HasPrefix(out, expected) {
+		t.Errorf("Pattern mismatch,\n\texpected prefix: %q\n\tgot: %q", expected, out)
+	}
+
+	buf.Reset()
+	_ = l.SetTheme(ThemeBasic)
+	l.Println(LevelError, "Basic")
+	if out := buf.String(); !strings.HasPrefix(out, string(levelColors[LevelError])) {
+		t.Errorf("Pattern mismatch,\n\texpected prefix: %q\n\tgot: %q", levelColors[LevelError], out)
+	}
+}
+
+func TestColorScheme(t *testing.T) {
+	if envNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagColorMode)
+	scheme := map[Level][]byte{
+		LevelFatal: ColorRGB(255, 0, 0),
+		LevelError: ColorRGB(200, 0, 0),
+		LevelWarn:  ColorRGB(200, 200, 0),
+		LevelInfo:  ColorRGB(0, 200, 0),
+		LevelDebug: ColorRGB(0, 0, 200),
+	}
+	if err := l.SetColorScheme(map[Level][]byte{LevelQuiet: ColorRGB(0, 0, 0)}); err == nil {
+		t.Error("Scheme with an unknown level was accepted")
+	}
+	for _, color := range [][]byte{{}, []byte("red"), []byte("\033[31")} {
+		if err := l.SetColorScheme(map[Level][]byte{LevelDebug: color}); err == nil {
+			t.Errorf("Scheme with the invalid color %q was accepted", color)
+		}
+	}
+	if err := l.SetColorScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	l.SetLevelColor(LevelInfo, ColorRGB(1, 2, 3))
+	l.SetLevelColor(LevelWarn, []byte{})
+	scheme[LevelInfo], scheme[LevelTrace] = ColorRGB(1, 2, 3), levelColors[LevelTrace]
+	for level := LevelError; level <= LevelTrace; level++ {
+		buf.Reset()
+		l.Println(level, "Schemed")
+		if out := buf.String(); !strings.HasPrefix(out, string(scheme[level])) {
+			t.Errorf("Pattern mismatch for level %d,\n\texpected prefix: %q\n\tgot: %q", level, scheme[level], out)
+		}
+	}
+
+	buf.Reset()
+	l.SetLevelColor(LevelInfo, nil)
+	_ = l.SetColorScheme(nil)
+	l.Println(LevelWarn, "Default")
+	if out := buf.String(); !strings.HasPrefix(out, string(levelColors[LevelWarn])) {
+		t.Errorf("Pattern mismatch,\n\texpected prefix: %q\n\tgot: %q", levelColors[LevelWarn], out)
+	}
+}
+
+func TestColorCache(t *testing.T) {
+	if envNoColor {
+		t.Skip("NO_COLOR is set")
+	}
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagColorMode)
+	check := func(step string, level Level, prefix []byte) {
+		t.Helper()
+		buf.Reset()
+		l.Println(level, "Cached")
+		out := buf.String()
+		if prefix == nil && strings.Contains(out, "\033[") {
+			t.Errorf("Unexpected color after %s: %q", step, out)
+		} else if prefix != nil && !strings.HasPrefix(out, string(prefix)) {
+			t.Errorf("Pattern mismatch after %s,\n\texpected prefix: %q\n\tgot: %q", step, prefix, out)
+		}
+	}
+	check("New", LevelError, levelColors[LevelError])
+	l.SetLevelColor(LevelError, ColorRGB(1, 2, 3))
+	check("SetLevelColor", LevelError, ColorRGB(1, 2, 3))
+	check("SetLevelColor", LevelWarn, levelColors[LevelWarn])
+	l.SetLevelStyle(LevelError, Underline)
+	check("SetLevelStyle", LevelError, composeStyle(ColorRGB(1, 2, 3), []SGR{Underline}))
+	l.SetFlags(0)
+	check("SetFlags", LevelError, nil)
+	l.SetFlags(FlagColorMode)
+	l.SetLevelStyle(LevelError)
+	l.SetLevelColor(LevelError, nil)
+	check("reset", LevelError, levelColors[LevelError])
+	if !envForceColor {
+		l.SetFlags(FlagAutoColor)
+		check("SetFlags", LevelError, nil)
+	}
+}
+
+func TestComposeStyle(t *testing.T) {
+	tests := []struct {
+		color    string
+		expected string
+	}{
+		{"\033[31m", "\033[31;1;4m"},
+		{"\033[38;2;1;2;3m", "\033[38;2;1;2;3;1;4m"},
+		{"", "\033[1;4m"},
+		{"\033[m", "\033[1;4m"},
+		{"red", "\033[1;4m"},
+		{"\033[31", "\033[1;4m"},
+	}
+	for _, test := range tests {
+		if got := string(composeStyle([]byte(test.color), []SGR{Bold, Underline})); got != test.expected {
+			t.Errorf("Pattern mismatch for %q,\n\texpected: %q\n\tgot: %q", test.color, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// config is the JSON form of the configuration of a logger, see ILogger.MarshalConfig.
+type config struct {
+	Level        string            `json:"level"`
+	Flags        int               `json:"flags"`
+	Prefix       string            `json:"prefix,omitempty"`
+	PrefixTime   bool              `json:"prefix_time,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	BodySep      string            `json:"body_separator,omitempty"`
+	MaxLineLen   int               `json:"max_line_length,omitempty"`
+	Newline      NewlineStyle      `json:"newline,omitempty"`
+	FieldOrder   FieldOrder        `json:"field_order,omitempty"`
+	MaxFields    int               `json:"max_fields,omitempty"`
+	Output       string            `json:"output,omitempty"`
+	FatalExits   *bool             `json:"fatal_exits,omitempty"`
+	WriteTimeout int64             `json:"write_timeout_ns,omitempty"`
+}
+
+// Output names used in configurations, any other name is the path of a file.
+const (
+	outputStderr  = "stderr"
+	outputStdout  = "stdout"
+	outputDiscard = "discard"
+)
+
+// levelName returns the name of level as accepted by parseLevel.
+func levelName(level Level) string {
+	if level == LevelQuiet {
+		return "quiet"
+	}
+	return levelNames[level]
+}
+
+// outputName returns the name of out in a configuration, or an empty string if it can not be named.
+func outputName(out io.Writer) string {
+	switch w := out.(type) {
+	case nil:
+		return outputDiscard
+	case *os.File:
+		switch w {
+		case os.Stderr:
+			return outputStderr
+		case os.Stdout:
+			return outputStdout
+		}
+		return w.Name()
+	case *ReopenableFileWriter:
+		return w.path
+	}
+	if out == io.Discard {
+		return outputDiscard
+	}
+	return ""
+}
+
+func (l *logger) MarshalConfig() ([]byte, error) {
+	fatalExits := atomic.LoadInt32(&l.fatalExits) != 0
+	l.Lock()
+	c := config{
+		Level:        levelName(Level(atomic.LoadInt32(&l.level))),
+		Flags:        l.flags,
+		Prefix:       l.prefix,
+		PrefixTime:   l.prefixTime,
+		BodySep:      l.bodySep,
+		MaxLineLen:   l.maxLineLen,
+		Newline:      l.newline,
+		FieldOrder:   l.fieldOrder,
+		MaxFields:    l.maxFields,
+		Output:       outputName(l.out),
+		FatalExits:   &fatalExits,
+		WriteTimeout: int64(l.writeTimeout),
+	}
+	for level, label := range l.labels {
+		if c.Labels == nil {
+			c.Labels = make(map[string]string, len(l.labels))
+		}
+		c.Labels[levelName(level)] = label
+	}
+	l.Unlock()
+	return json.Marshal(c)
+}
+
+func (l *logger) LoadConfig(data []byte) error {
+	var c config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+	level, ok := parseLevel(c.Level)
+	if !ok {
+		return errors.New("logger: invalid level " + c.Level)
+	}
+	labels := make(map[Level]string, len(c.Labels))
+	for name, label := range c.Labels {
+		lv, ok := parseLevel(name)
+		if !ok {
+			return errors.New("logger: invalid label level " + name)
+		}
+		labels[lv] = label
+	}
+	if err := c.validate(); err != nil {
+		return err
+	}
+	out, err := l.openOutput(c.Output)
+	if err != nil {
+		return err
+	}
+
+	l.SetLevel(level)
+	// An omitted fatal_exits, e.g. in a hand written configuration, keeps the current setting.
+	if c.FatalExits != nil {
+		l.SetFatalExits(*c.FatalExits)
+	}
+	l.Lock()
+	l.flags, l.prefix, l.bodySep, l.maxLineLen, l.newline = c.Flags, c.Prefix, c.BodySep, c.MaxLineLen, c.Newline
+	l.fieldOrder, l.maxFields, l.writeTimeout = c.FieldOrder, c.MaxFields, time.Duration(c.WriteTimeout)
+	l.prefixTime = c.PrefixTime && strings.Contains(c.Prefix, timePlaceholder)
+	l.labels = labels
+	l.resetHeaders()
+	l.resetColors()
+	l.Unlock()
+	if out != nil || c.Output == outputDiscard {
+		l.SetOutput(out)
+		// The logger owns the files it opens: Close closes the current one, and a replaced one is closed here.
+		var closer io.Closer
+		if f, ok := out.(*os.File); ok && f != os.Stderr && f != os.Stdout {
+			closer = f
+		}
+		l.Lock()
+		prev := l.closer
+		l.closer = closer
+		l.Unlock()
+		if prev != nil {
+			_ = prev.Close()
+		}
+	}
+	return nil
+}
+
+// validate checks the values which the JSON decoding can not, rather than storing unknown flags or styles.
+func (c *config) validate() error {
+	known := 0
+	for _, f := range flagNames {
+		known |= f.flag
+	}
+	if c.Flags&^known != 0 {
+		return errors.New("logger: invalid flags " + strconv.Itoa(c.Flags))
+	}
+	if c.Newline != NewlineLF && c.Newline != NewlineCRLF {
+		return errors.New("logger: invalid newline " + strconv.Itoa(int(c.Newline)))
+	}
+	if c.FieldOrder != FieldOrderSorted && c.FieldOrder != FieldOrderInsertion {
+		return errors.New("logger: invalid field order " + strconv.Itoa(int(c.FieldOrder)))
+	}
+	return nil
+}
+
+// openOutput returns the writer named by name, or nil if it is the current output, can not be named or is discard.
+// Files are opened for appending, missing parent directories are created.
+func (l *logger) openOutput(name string) (io.Writer, error) {
+	switch name {
+	case "", outputDiscard:
+		return nil, nil
+	case outputStderr:
+		return os.Stderr, nil
+	case outputStdout:
+		return os.Stdout, nil
+	}
+	if outputName(l.GetOutput()) == name {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+	f, err := openLogFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
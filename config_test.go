@@ -0,0 +1,140 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	src, err := NewFileLogger(path, LevelDebug, FlagNumericLevel|FlagIndentMultiline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.GetOutput().(*os.File).Close()
+	src.SetPrefix("APP")
+	src.SetLevelLabel(LevelError, "[ERROR]")
+	src.SetBodySeparator(" | ")
+	src.SetMaxLineLength(100)
+	src.SetFieldOrder(FieldOrderInsertion)
+	src.SetMaxFields(5)
+	src.SetFatalExits(false)
+	src.SetWriteTimeout(time.Second)
+	data, err := src.MarshalConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log("Got: ", string(data))
+
+	dst := New(LevelWarn, "", new(bytes.Buffer), 0)
+	if err := dst.LoadConfig(data); err != nil {
+		t.Fatal(err)
+	}
+	defer dst.GetOutput().(*os.File).Close()
+	got, err := dst.MarshalConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", data, got)
+	}
+	if dst.GetLevel() != LevelDebug || dst.GetPrefix() != "APP" || dst.GetFlags() != FlagNumericLevel|FlagIndentMultiline {
+		t.Errorf("Configuration was not restored: %s", got)
+	}
+
+	// Both instances append to the same file.
+	src.Println(LevelInfo, "from source")
+	dst.Println(LevelError, "from restored")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(content); !strings.Contains(s, " APP:  | from source\n") || !strings.Contains(s, " APP:  | from restored\n") {
+		t.Errorf("Pattern mismatch,\n\texpected: both entries\n\tgot: %s", s)
+	}
+}
+
+func TestConfigOutputs(t *testing.T) {
+	l := New(LevelWarn, "", os.Stdout, 0)
+	for _, name := range []string{"stderr", "stdout", "discard"} {
+		if err := l.LoadConfig([]byte(`{"level":"info","output":"` + name + `"}`)); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := l.MarshalConfig()
+		if !strings.Contains(string(data), `"output":"`+name+`"`) {
+			t.Errorf("Pattern mismatch,\n\texpected: %s output\n\tgot: %s", name, data)
+		}
+	}
+
+	// Unnamed outputs are omitted and kept as is.
+	buf := new(bytes.Buffer)
+	l.SetOutput(buf)
+	data, _ := l.MarshalConfig()
+	if strings.Contains(string(data), "output") {
+		t.Errorf("Unnamed output was serialized: %s", data)
+	}
+	if err := l.LoadConfig(data); err != nil || l.GetOutput() != buf {
+		t.Errorf("Unnamed output was not kept: %v", err)
+	}
+
+	// Invalid configurations change nothing.
+	for _, data := range []string{
+		`{"level":"loud"}`, `{"level":"info","labels":{"loud":"!"}}`, `not json`,
+		`{"level":"info","flags":1048576}`, `{"level":"info","newline":2}`, `{"level":"info","field_order":-1}`,
+	} {
+		if err := l.LoadConfig([]byte(data)); err == nil {
+			t.Errorf("Invalid configuration was accepted: %s", data)
+		}
+	}
+	if l.GetLevel() != LevelInfo {
+		t.Errorf("Pattern mismatch,\n\texpected: %d\n\tgot: %d", LevelInfo, l.GetLevel())
+	}
+
+	// An omitted fatal_exits keeps the current setting.
+	for _, exits := range []bool{false, true} {
+		l.SetFatalExits(exits)
+		if err := l.LoadConfig([]byte(`{"level":"info"}`)); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := l.MarshalConfig()
+		if want := fmt.Sprintf(`"fatal_exits":%t`, exits); !strings.Contains(string(data), want) {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", want, data)
+		}
+	}
+}
+
+func TestConfigReload(t *testing.T) {
+	dir := t.TempDir()
+	l := New(LevelWarn, "", nil, 0)
+	var files []*os.File
+	for _, name := range []string{"first.log", "second.log"} {
+		data, err := json.Marshal(map[string]any{"level": "info", "output": filepath.Join(dir, name)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.LoadConfig(data); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, l.GetOutput().(*os.File))
+	}
+	// The file of the first configuration was closed when it was replaced, the second one by Close.
+	if _, err := files[0].Write([]byte("Leaked\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", os.ErrClosed, err)
+	}
+	l.Println(LevelInfo, "Reloaded")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := files[1].Write([]byte("Leaked\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", os.ErrClosed, err)
+	}
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// ContextExtractor returns the fields to be added to entries logged with a context, e.g. a request id.
+// It is called for every entry logged by ILogger.PrintContext and must be safe for concurrent use.
+type ContextExtractor func(ctx context.Context) []Field
+
+// DeadlineExtractor is a ContextExtractor adding a deadline_remaining field with the time left before
+// the deadline of the context, rounded to the millisecond. Contexts without a deadline get no field.
+func DeadlineExtractor(ctx context.Context) []Field {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	return []Field{String("deadline_remaining", time.Until(deadline).Round(time.Millisecond).String())}
+}
+
+func (l *logger) AddContextExtractor(extractor ContextExtractor) {
+	l.Lock()
+	defer l.Unlock()
+	// Always copy, the extractors slice may be in use by PrintContext.
+	l.extractors = append(l.extractors[:len(l.extractors):len(l.extractors)], extractor)
+}
+
+func (l *logger) PrintContext(ctx context.Context, level Level, msg string, fields ...Field) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
+	}
+	l.Lock()
+	extractors := l.extractors
+	l.Unlock()
+	if len(extractors) > 0 {
+		// Never append to the caller's slice.
+		fields = fields[:len(fields):len(fields)]
+		for _, extract := range extractors {
+			fields = append(fields, extract(ctx)...)
+		}
+	}
+	l.handleError(l.printOut(level, fields, func(b *buffer) { *b = append(*b, msg...) }))
+	l.exitIfFatal(level)
+}
+
+// loggerKey is the context key of the logger stored by NewContext.
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying l, to be retrieved by FromContext.
+// It lets request-scoped code receive its logger without global state.
+func NewContext(ctx context.Context, l ILogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the default instance if there is none.
+func FromContext(ctx context.Context) ILogger {
+	if l, ok := ctx.Value(loggerKey{}).(ILogger); ok && l != nil {
+		return l
+	}
+	return std
+}
@@ -0,0 +1,60 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type requestIDKey struct{}
+
+func TestPrintContext(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetFieldOrder(FieldOrderInsertion)
+	l.AddContextExtractor(DeadlineExtractor)
+	l.AddContextExtractor(func(ctx context.Context) []Field {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return []Field{String("request_id", id)}
+		}
+		return nil
+	})
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), requestIDKey{}, "r-42"), 2*time.Second)
+	defer cancel()
+	l.PrintContext(ctx, LevelWarn, "Slow query", Int("rows", 3))
+	out := buf.String()
+	t.Log("Got: ", out)
+	expected := regexp.MustCompile(`^Slow query rows=3 deadline_remaining=(1\.\d+|2)s request_id=r-42\n$`)
+	if !expected.MatchString(out[13:]) {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out[13:])
+	}
+
+	// Contexts without deadline nor value get no extra fields.
+	buf.Reset()
+	l.Clone().PrintContext(context.Background(), LevelWarn, "Plain")
+	if out = buf.String(); out[13:] != "Plain\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "Plain", out[13:])
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if l := FromContext(context.Background()); l != GetDefault() {
+		t.Errorf("Default instance was not returned for an empty context")
+	}
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "REQ", buf, 0)
+	ctx := NewContext(context.Background(), l)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	FromContext(ctx).Println(LevelInfo, "Scoped entry")
+	if out := buf.String(); out[11:] != "REQ: Scoped entry\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "REQ: Scoped entry", out[11:])
+	}
+	if l := FromContext(NewContext(ctx, nil)); l != GetDefault() {
+		t.Errorf("Default instance was not returned for a nil logger")
+	}
+}
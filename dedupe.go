@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidState is returned by NewDedupeWriter when the state file cannot be parsed.
+var ErrInvalidState = errors.New("logger: invalid dedupe state")
+
+// DedupeWriter is an io.Writer for idempotent shippers, which avoids emitting records again after a restart.
+// It persists the number of records written so far and their chained hash to a state file after every write.
+// When a process replays the same records after a restart, e.g. a batch pipeline running again, the ones
+// already shipped are skipped and writing resumes with the first new record.
+//
+// The skipped records are checked against the stored hash once all of them were seen: if they differ, the
+// replay is not the same and the last one is written, with all the following. Records are the writes of
+// the logger, i.e. entries, so every entry must be the same across runs, use a fixed clock or no time.
+type DedupeWriter struct {
+	w    io.Writer
+	path string
+	// count and sum are the number of records seen and their chained hash. The first skip records are
+	// not written, their chained hash must be skipSum.
+	count   uint64
+	sum     [sha256.Size]byte
+	skip    uint64
+	skipSum [sha256.Size]byte
+	sync.Mutex
+}
+
+// NewDedupeWriter returns a DedupeWriter writing to w and keeping its state in the file at statePath.
+// If the file exists, the records it tells about are skipped. A missing file starts a new state.
+func NewDedupeWriter(w io.Writer, statePath string) (*DedupeWriter, error) {
+	d := &DedupeWriter{w: w, path: statePath}
+	b, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if d.skip, d.skipSum, err = parseDedupeState(string(b)); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// parseDedupeState parses a state, i.e. "<count> <hex encoded hash>\n".
+func parseDedupeState(s string) (count uint64, sum [sha256.Size]byte, err error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return 0, sum, ErrInvalidState
+	}
+	if count, err = strconv.ParseUint(fields[0], 10, 64); err != nil {
+		return 0, sum, ErrInvalidState
+	}
+	if len(fields[1]) != 2*sha256.Size {
+		return 0, sum, ErrInvalidState
+	}
+	if _, err = hex.Decode(sum[:], []byte(fields[1])); err != nil {
+		return 0, sum, ErrInvalidState
+	}
+	return count, sum, nil
+}
+
+// Write writes p unless it was already shipped before a restart, then saves the state.
+// Records failing to be written do not change the state, so they are written again after a restart.
+func (d *DedupeWriter) Write(p []byte) (int, error) {
+	d.Lock()
+	defer d.Unlock()
+	sum := chainHash(d.sum, p)
+	// The record was shipped before, unless it is the last one to skip and the replay turned out different.
+	if d.count < d.skip && (d.count+1 < d.skip || sum == d.skipSum) {
+		d.count, d.sum = d.count+1, sum
+		return len(p), nil
+	}
+	d.skip = 0
+	if _, err := d.w.Write(p); err != nil {
+		return 0, err
+	}
+	d.count, d.sum = d.count+1, sum
+	if err := d.save(); err != nil {
+		return len(p), err
+	}
+	return len(p), nil
+}
+
+// save writes the state to a temporary file and renames it over the state file, so that a crash while
+// saving leaves the previous state. It must be called with the lock held.
+func (d *DedupeWriter) save() error {
+	tmp := d.path + ".tmp"
+	state := fmt.Sprintf("%d %s\n", d.count, hex.EncodeToString(d.sum[:]))
+	if err := os.WriteFile(tmp, []byte(state), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, d.path)
+}
@@ -0,0 +1,76 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// runBatch logs the entries through a new DedupeWriter, like a run of a batch pipeline, and returns the output.
+func runBatch(t *testing.T, state string, entries ...string) string {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	d, err := NewDedupeWriter(buf, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(LevelTrace, "", d, FlagSequence)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	for _, e := range entries {
+		l.Println(LevelInfo, e)
+	}
+	return buf.String()
+}
+
+func TestDedupeWriter(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "ship.state")
+	out := runBatch(t, state, "first", "second", "third")
+	expected := "I/10:00:00 #1 : first\nI/10:00:00 #2 : second\nI/10:00:00 #3 : third\n"
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, out)
+	}
+
+	// After a restart, the shipped entries are not emitted again.
+	out = runBatch(t, state, "first", "second", "third", "fourth", "fifth")
+	expected = "I/10:00:00 #4 : fourth\nI/10:00:00 #5 : fifth\n"
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, out)
+	}
+	if out = runBatch(t, state, "first", "second", "third", "fourth", "fifth"); out != "" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "", out)
+	}
+
+	// A different replay writes from the first entry found to differ once all shipped ones were seen.
+	out = runBatch(t, state, "first", "second", "third", "fourth", "changed", "sixth")
+	expected = "I/10:00:00 #5 : changed\nI/10:00:00 #6 : sixth\n"
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, out)
+	}
+}
+
+func TestDedupeWriterFailure(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "ship.state")
+	d, err := NewDedupeWriter(failingWriter{}, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = d.Write([]byte("lost\n")); err == nil {
+		t.Error("Write error was not returned")
+	}
+	// Failed records are not part of the state, so they are written again after a restart.
+	if out := runBatch(t, state, "lost"); out != "I/10:00:00 #1 : lost\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "I/10:00:00 #1 : lost\n", out)
+	}
+
+	if err = os.WriteFile(state, []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewDedupeWriter(new(bytes.Buffer), state); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", ErrInvalidState, err)
+	}
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// These environment variables override the configuration of the default instance at start up.
+// GOLOGGER_LEVEL takes a level name like "debug" or its number, GOLOGGER_FLAGS takes the flags as a number
+// or as names like "color,indent", see ILogger.SetFlagsFromString.
+const (
+	EnvLevel  = "GOLOGGER_LEVEL"
+	EnvFlags  = "GOLOGGER_FLAGS"
+	EnvPrefix = "GOLOGGER_PREFIX"
+)
+
+func init() {
+	applyEnv(std, os.LookupEnv)
+}
+
+// applyEnv configures l from the environment variables found by lookupEnv, invalid values are ignored.
+func applyEnv(l ILogger, lookupEnv func(key string) (string, bool)) {
+	if s, ok := lookupEnv(EnvLevel); ok {
+		if level, ok := parseLevel(s); ok {
+			l.SetLevel(level)
+		}
+	}
+	if s, ok := lookupEnv(EnvFlags); ok {
+		if flags, err := strconv.Atoi(s); err == nil {
+			l.SetFlags(flags)
+		} else {
+			_ = l.SetFlagsFromString(s)
+		}
+	}
+	if prefix, ok := lookupEnv(EnvPrefix); ok {
+		l.SetPrefix(prefix)
+	}
+}
+
+// parseLevel parses a level name like "warn" (case-insensitively) or a level number.
+func parseLevel(s string) (Level, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "quiet" {
+		return LevelQuiet, true
+	}
+	for level, name := range levelNames {
+		if s == name {
+			return level, true
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= int(LevelQuiet) && n <= int(LevelTrace) {
+		return Level(n), true
+	}
+	return LevelQuiet, false
+}
+
+// SetDefaultLevel sets the level of the default instance used by package-level functions.
+func SetDefaultLevel(level Level) {
+	std.SetLevel(level)
+}
+
+// SetDefaultFlags sets the flags of the default instance used by package-level functions.
+func SetDefaultFlags(flags int) {
+	std.SetFlags(flags)
+}
+
+// SetDefaultPrefix sets the prefix of the default instance used by package-level functions.
+func SetDefaultPrefix(prefix string) {
+	std.SetPrefix(prefix)
+}
@@ -0,0 +1,62 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func TestSetDefaults(t *testing.T) {
+	l := GetDefault()
+	out, level, flags, prefix := l.GetOutput(), l.GetLevel(), l.GetFlags(), l.GetPrefix()
+	defer func() {
+		l.SetOutput(out)
+		SetDefaultLevel(level)
+		SetDefaultFlags(flags)
+		SetDefaultPrefix(prefix)
+	}()
+
+	buf := new(bytes.Buffer)
+	l.SetOutput(buf)
+	SetDefaultLevel(LevelDebug)
+	SetDefaultFlags(FlagNumericLevel)
+	SetDefaultPrefix("APP")
+	Println(LevelDebug, "Using defaults")
+	got := buf.String()
+	expected := "APP: Using defaults\n"
+	t.Log("Got: ", got)
+	if got[:2] != "5/" || got[11:] != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, got)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		EnvLevel:  "Debug",
+		EnvFlags:  strconv.Itoa(FlagColorMode),
+		EnvPrefix: "",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	l := New(LevelWarn, "BEFORE", nil, 0)
+	applyEnv(l, lookup)
+	if l.GetLevel() != LevelDebug || l.GetFlags() != FlagColorMode || l.GetPrefix() != "" {
+		t.Errorf("Environment was not applied: %v %v %q", l.GetLevel(), l.GetFlags(), l.GetPrefix())
+	}
+
+	// Invalid or missing values are ignored.
+	env = map[string]string{EnvLevel: "loud", EnvFlags: "many"}
+	applyEnv(l, lookup)
+	if l.GetLevel() != LevelDebug || l.GetFlags() != FlagColorMode {
+		t.Errorf("Invalid environment was applied: %v %v", l.GetLevel(), l.GetFlags())
+	}
+	env = map[string]string{EnvLevel: "3", EnvFlags: "indent, sequence"}
+	applyEnv(l, lookup)
+	if l.GetLevel() != LevelWarn || l.GetFlags() != FlagIndentMultiline|FlagSequence {
+		t.Errorf("Pattern mismatch,\n\texpected: %v %v\n\tgot: %v %v", LevelWarn, FlagIndentMultiline|FlagSequence, l.GetLevel(), l.GetFlags())
+	}
+}
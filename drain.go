@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+func (l *logger) Drain(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- l.drain() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *logger) Close() error {
+	err := l.drain()
+	l.Lock()
+	closers := l.levelClosers
+	if l.closer != nil {
+		closers = append(closers[:len(closers):len(closers)], l.closer)
+	}
+	l.closer, l.levelClosers = nil, nil
+	l.Unlock()
+	for _, c := range closers {
+		if e := c.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// closeWithin calls Close, giving up after timeout with context.DeadlineExceeded, the rest goes on in the background.
+func (l *logger) closeWithin(timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() { done <- l.Close() }()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return context.DeadlineExceeded
+	}
+}
+
+// drain does the work of Drain, without a deadline. It returns the first error.
+func (l *logger) drain() error {
+	l.Lock()
+	pending, hooks, drops := l.pending, l.hooks, l.drops
+	outputs := []io.Writer{l.out}
+	for _, out := range l.levelOut {
+		outputs = append(outputs, out)
+	}
+	if l.coarse != nil {
+		l.stopCoarseClock()
+		l.now = time.Now
+	}
+	l.Unlock()
+
+	// A write which timed out may still complete.
+	if pending != nil {
+		<-pending
+	}
+	if drops != nil {
+		l.writeDropSummary(drops)
+		l.SetDropSummary(0)
+	}
+	var first error
+	for _, out := range outputs {
+		if out == nil {
+			continue
+		}
+		if err := flushOutput(out); err != nil && first == nil {
+			first = err
+		}
+	}
+	for _, h := range hooks {
+		if c, ok := h.(io.Closer); ok {
+			if err := c.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
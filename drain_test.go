@@ -0,0 +1,74 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingFlusher is an output whose Flush blocks until release is closed.
+type blockingFlusher struct {
+	release chan struct{}
+}
+
+func (w *blockingFlusher) Write(p []byte) (int, error) { return len(p), nil }
+
+func (w *blockingFlusher) Flush() error {
+	<-w.release
+	return nil
+}
+
+func TestDrain(t *testing.T) {
+	var posted int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&posted, 1)
+	}))
+	defer server.Close()
+
+	out := new(countingWriter)
+	batch := NewBatchWriter(out, 4096, 0)
+	l := New(LevelTrace, "", batch, 0)
+	hook := WebhookHook(server.URL, LevelError)
+	l.AddHook(hook)
+	l.SetCoarseTime(time.Millisecond)
+	l.Println(LevelError, "Pending everywhere")
+	if out.String() != "" || atomic.LoadInt32(&posted) != 0 {
+		t.Fatal("Entry was not pending")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := l.Drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got[13:] != "Pending everywhere\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "Pending everywhere\n", got)
+	}
+	if n := atomic.LoadInt32(&posted); n != 1 {
+		t.Errorf("Pattern mismatch,\n\texpected: 1 post\n\tgot: %d", n)
+	}
+	if l.(*logger).coarse != nil {
+		t.Error("Coarse clock is still running")
+	}
+}
+
+func TestDrainCanceled(t *testing.T) {
+	out := &blockingFlusher{release: make(chan struct{})}
+	defer close(out.release)
+	l := New(LevelTrace, "", out, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Drain(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", context.DeadlineExceeded, err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Drain returned after %s", d)
+	}
+}
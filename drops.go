@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// dropSummary counts the entries dropped by a logger per level, see SetDropSummary.
+type dropSummary struct {
+	interval time.Duration
+	counts   [LevelTrace + 1]int
+	stop     chan struct{}
+}
+
+func (l *logger) SetDropSummary(interval time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	if l.drops != nil {
+		close(l.drops.stop)
+		l.drops = nil
+	}
+	if interval <= 0 {
+		return
+	}
+	d := &dropSummary{interval: interval, stop: make(chan struct{})}
+	l.drops = d
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.writeDropSummary(d)
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+// countDrop counts a dropped entry of level if drop summaries are enabled. It must be called with the lock held.
+func (l *logger) countDrop(level Level) {
+	if l.drops != nil && level > LevelQuiet && level <= LevelTrace {
+		l.drops.counts[level]++
+	}
+}
+
+// countFailedWrite counts the entry of level as dropped if writing it timed out. It must be called with the lock held.
+func (l *logger) countFailedWrite(level Level, e error) {
+	if e != nil && errors.Is(e, ErrWriteTimeout) {
+		l.countDrop(level)
+	}
+}
+
+// writeDropSummary writes a LevelWarn entry like "dropped: error=12 warn=3" if entries were dropped since
+// the previous one, and resets the counts. Like the recursion warning, it bypasses quiet windows and hooks,
+// but not the level: nothing is written while LevelWarn is disabled.
+func (l *logger) writeDropSummary(d *dropSummary) {
+	l.Lock()
+	defer l.Unlock()
+	if l.drops != d {
+		return
+	}
+	msg := []byte("dropped:")
+	for level := LevelFatal; level <= LevelTrace; level++ {
+		if n := d.counts[level]; n > 0 {
+			msg = append(msg, ' ')
+			msg = append(msg, levelNames[level]...)
+			msg = append(msg, '=')
+			msg = strconv.AppendInt(msg, int64(n), 10)
+		}
+	}
+	if len(msg) == len("dropped:") {
+		return
+	}
+	d.counts = [LevelTrace + 1]int{}
+	if atomic.LoadInt32(&l.level) < int32(LevelWarn) {
+		return
+	}
+	_, _, e := l.format(nil, l.now(), LevelWarn, nil, func(b *buffer) { *b = append(*b, msg...) })
+	l.countFailedWrite(LevelWarn, e)
+}
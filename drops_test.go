@@ -0,0 +1,55 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDropSummary(t *testing.T) {
+	buf := NewBoundedBufferWriter(4096, DropNewest)
+	l := New(LevelTrace, "", buf, 0)
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	l.SetTimeFunc(func() time.Time { return now })
+	l.AddQuietWindow(now, now.Add(time.Hour), LevelError, LevelInfo)
+	l.SetDropSummary(20 * time.Millisecond)
+	defer l.SetDropSummary(0)
+	for i := 0; i < 3; i++ {
+		l.Println(LevelInfo, "Silenced")
+	}
+	l.Println(LevelError, "Silenced")
+	l.Println(LevelError, "Silenced")
+	l.Println(LevelWarn, "Written")
+
+	expected := "W/10:00:00 : Written\nW/10:00:00 : dropped: error=2 info=3\n"
+	deadline := time.Now().Add(time.Second)
+	for string(buf.Bytes()) != expected && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := string(buf.Bytes()); got != expected {
+		t.Fatalf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+	// The counts are reset, nothing is written while no entry is dropped.
+	time.Sleep(60 * time.Millisecond)
+	if got := string(buf.Bytes()); strings.Count(got, "dropped:") != 1 {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+}
+
+func TestDropSummaryLevel(t *testing.T) {
+	buf := NewBoundedBufferWriter(4096, DropNewest)
+	l := New(LevelError, "", buf, 0)
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	l.SetTimeFunc(func() time.Time { return now })
+	l.AddQuietWindow(now, now.Add(time.Hour), LevelError)
+	l.SetDropSummary(10 * time.Millisecond)
+	defer l.SetDropSummary(0)
+	l.Println(LevelError, "Silenced")
+	// The summary is a LevelWarn entry, which is disabled.
+	time.Sleep(60 * time.Millisecond)
+	if got := string(buf.Bytes()); got != "" {
+		t.Errorf("Pattern mismatch,\n\texpected: no output\n\tgot: %q", got)
+	}
+}
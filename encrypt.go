@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// maxRecordLen bounds the records read by a DecryptingReader, so a corrupted length can not exhaust memory.
+const maxRecordLen = 16 << 20
+
+// ErrInvalidRecord is returned by a DecryptingReader when a record is too large or fails authentication,
+// e.g. because it was tampered with or encrypted with another key.
+var ErrInvalidRecord = errors.New("logger: invalid encrypted record")
+
+// EncryptingWriter is an io.Writer encrypting every write with AES-GCM before passing it to the underlying writer.
+// Each write, i.e. each entry, becomes a record made of a 4 bytes big-endian length followed by a random nonce
+// and the sealed data. Random nonces are safe for up to 2^32 records per key, rotate keys before that.
+// Records are decrypted by a DecryptingReader.
+type EncryptingWriter struct {
+	w    io.Writer
+	aead cipher.AEAD
+	buf  []byte
+	sync.Mutex
+}
+
+// newGCM returns an AES-GCM cipher for key, which must be 16, 24 or 32 bytes long for AES-128, AES-192 or AES-256.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// NewEncryptingWriter returns an EncryptingWriter writing records to w. The key must be 16, 24 or 32 bytes long.
+func NewEncryptingWriter(w io.Writer, key []byte) (*EncryptingWriter, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptingWriter{w: w, aead: aead}, nil
+}
+
+// Write encrypts p and writes it as one record to the underlying writer.
+func (w *EncryptingWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	size := w.aead.NonceSize() + len(p) + w.aead.Overhead()
+	if cap(w.buf) < 4+size {
+		w.buf = make([]byte, 4+size)
+	}
+	b := w.buf[:4+w.aead.NonceSize()]
+	binary.BigEndian.PutUint32(b, uint32(size))
+	nonce := b[4:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return 0, err
+	}
+	b = w.aead.Seal(b, nonce, p, nil)
+	if _, err := w.w.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// DecryptingReader is an io.Reader returning the plaintext of the records written by an EncryptingWriter.
+type DecryptingReader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	record []byte
+	plain  []byte
+}
+
+// NewDecryptingReader returns a DecryptingReader reading records from r, encrypted with key.
+func NewDecryptingReader(r io.Reader, key []byte) (*DecryptingReader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptingReader{r: r, aead: aead}, nil
+}
+
+// Read fills p with decrypted data, records larger than p are returned over several reads.
+// It returns io.EOF at the end of the last record, or io.ErrUnexpectedEOF if the last record is truncated.
+func (r *DecryptingReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// next reads and decrypts the next record.
+func (r *DecryptingReader) next() error {
+	var size [4]byte
+	if _, err := io.ReadFull(r.r, size[:]); err != nil {
+		return err
+	}
+	n := int(binary.BigEndian.Uint32(size[:]))
+	if n < r.aead.NonceSize()+r.aead.Overhead() || n > maxRecordLen {
+		return ErrInvalidRecord
+	}
+	if cap(r.record) < n {
+		r.record = make([]byte, n)
+	}
+	record := r.record[:n]
+	if _, err := io.ReadFull(r.r, record); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	nonce, sealed := record[:r.aead.NonceSize()], record[r.aead.NonceSize():]
+	plain, err := r.aead.Open(sealed[:0], nonce, sealed, nil)
+	if err != nil {
+		return ErrInvalidRecord
+	}
+	r.plain = plain
+	return nil
+}
@@ -0,0 +1,70 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestEncryptingWriter(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	sealed, plain := new(bytes.Buffer), new(bytes.Buffer)
+	w, err := NewEncryptingWriter(sealed, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) }
+	for _, out := range []io.Writer{w, plain} {
+		l := New(LevelTrace, "SECRET", out, 0)
+		l.SetTimeFunc(now)
+		l.Println(LevelInfo, "Card number accepted")
+		l.Println(LevelInfo, "Card number accepted")
+		l.Printf(LevelWarn, "Multi\nline %d", 3)
+	}
+	if bytes.Contains(sealed.Bytes(), []byte("Card")) {
+		t.Fatalf("Plaintext leaked: %q", sealed.Bytes())
+	}
+	// Identical entries must not produce identical records, each one has its own nonce.
+	b := sealed.Bytes()
+	size := 4 + int(binary.BigEndian.Uint32(b))
+	if first, second := b[4:16], b[size+4:size+16]; bytes.Equal(first, second) || bytes.Equal(b[:size], b[size:2*size]) {
+		t.Errorf("Records share a nonce: %x", first)
+	}
+
+	// Decrypting works with partial reads, one byte at a time.
+	r, err := NewDecryptingReader(iotest.OneByteReader(bytes.NewReader(sealed.Bytes())), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(iotest.OneByteReader(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain.Bytes()) {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", plain.Bytes(), got)
+	}
+
+	tests := []struct {
+		data     []byte
+		key      []byte
+		expected error
+	}{
+		{sealed.Bytes()[:sealed.Len()-1], key, io.ErrUnexpectedEOF},
+		{sealed.Bytes(), []byte("fedcba9876543210fedcba9876543210"), ErrInvalidRecord},
+		{[]byte{0xff, 0xff, 0xff, 0xff}, key, ErrInvalidRecord},
+	}
+	for _, test := range tests {
+		r, _ := NewDecryptingReader(bytes.NewReader(test.data), test.key)
+		if _, err := io.ReadAll(r); err != test.expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", test.expected, err)
+		}
+	}
+	if _, err := NewEncryptingWriter(sealed, []byte("short")); err == nil {
+		t.Errorf("Invalid key was accepted")
+	}
+}
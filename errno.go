@@ -0,0 +1,8 @@
+//go:build !plan9
+
+package logger
+
+import "syscall"
+
+// permanentErrnos are the system errors making writes fail for good: broken pipes and bad descriptors.
+var permanentErrnos = []error{syscall.EPIPE, syscall.EBADF}
@@ -0,0 +1,4 @@
+package logger
+
+// permanentErrnos is empty, Plan 9 reports errors as strings without errno values.
+var permanentErrnos []error
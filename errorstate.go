@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// errorState is the Hook added by OnErrorStateChange. The state is entered by an error entry and left once
+// no error was logged for window, which is checked by a timer and by the time of the next entries.
+type errorState struct {
+	l       *logger
+	window  time.Duration
+	fn      func(inError bool)
+	inError bool
+	last    time.Time
+	// timer leaves the error state, gen tells which timer is the current one.
+	timer *time.Timer
+	gen   uint64
+	sync.Mutex
+}
+
+func (l *logger) OnErrorStateChange(window time.Duration, fn func(inError bool)) {
+	if window <= 0 || fn == nil {
+		return
+	}
+	l.AddHook(&errorState{l: l, window: window, fn: fn})
+}
+
+func (s *errorState) Fire(e *Entry) {
+	s.Lock()
+	if !IsAtLeast(e.Level, LevelError) {
+		left := s.inError && e.Time.Sub(s.last) >= s.window
+		if left {
+			s.leave()
+		}
+		s.Unlock()
+		if left {
+			s.fn(false)
+		}
+		return
+	}
+	s.last = e.Time
+	s.gen++
+	gen := s.gen
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(s.window, func() { s.expire(gen) })
+	entered := !s.inError
+	s.inError = true
+	s.Unlock()
+	// Hooks already run as callbacks of the logger, entries logged by fn are dropped.
+	if entered {
+		s.fn(true)
+	}
+}
+
+// expire leaves the error state if the timer gen is still the current one.
+func (s *errorState) expire(gen uint64) {
+	s.Lock()
+	left := s.inError && s.gen == gen
+	if left {
+		s.leave()
+	}
+	s.Unlock()
+	if left {
+		s.l.runCallback(func() { s.fn(false) })
+	}
+}
+
+// leave resets the state. It must be called with the lock held.
+func (s *errorState) leave() {
+	s.inError = false
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
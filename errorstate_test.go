@@ -0,0 +1,59 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOnErrorStateChange(t *testing.T) {
+	var mu sync.Mutex
+	var states []bool
+	got := func() []bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]bool(nil), states...)
+	}
+	l := New(LevelTrace, "", io.Discard, 0)
+	l.OnErrorStateChange(50*time.Millisecond, func(inError bool) {
+		mu.Lock()
+		states = append(states, inError)
+		mu.Unlock()
+	})
+	l.Println(LevelInfo, "Fine")
+	l.Println(LevelError, "Failed")
+	l.Println(LevelError, "Failed again")
+	l.Println(LevelWarn, "Not an error")
+	if s := got(); !reflect.DeepEqual(s, []bool{true}) {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", []bool{true}, s)
+	}
+	// The timer leaves the error state once the window passed without errors.
+	deadline := time.Now().Add(time.Second)
+	for len(got()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	l.Println(LevelError, "Failed later")
+	if s := got(); !reflect.DeepEqual(s, []bool{true, false, true}) {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", []bool{true, false, true}, s)
+	}
+}
+
+func TestOnErrorStateChangeClock(t *testing.T) {
+	var states []bool
+	l := New(LevelTrace, "", io.Discard, 0)
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	l.SetTimeFunc(func() time.Time { return now })
+	l.OnErrorStateChange(time.Hour, func(inError bool) { states = append(states, inError) })
+	l.Println(LevelError, "Failed")
+	now = now.Add(30 * time.Minute)
+	l.Println(LevelInfo, "Still in the window")
+	now = now.Add(30 * time.Minute)
+	l.Println(LevelInfo, "Recovered")
+	if !reflect.DeepEqual(states, []bool{true, false}) {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", []bool{true, false}, states)
+	}
+}
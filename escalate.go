@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"strconv"
+	"time"
+)
+
+// escalation is the configuration set by SetEscalation, with the repeats counted per message.
+type escalation struct {
+	from, to Level
+	count    int
+	window   time.Duration
+	repeats  map[string]*repeat
+}
+
+// repeat counts the occurrences of a message since start, escalated tells whether it was escalated since.
+type repeat struct {
+	start     time.Time
+	count     int
+	escalated bool
+}
+
+func (l *logger) SetEscalation(from, to Level, count int, window time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	if count <= 0 || window <= 0 || !to.MoreSevereThan(from) {
+		l.escalation = nil
+		return
+	}
+	l.escalation = &escalation{from: from, to: to, count: count, window: window, repeats: make(map[string]*repeat)}
+}
+
+// countRepeat counts an entry with the message msg and tells whether it reached the escalation threshold,
+// which happens once per window. It must be called with the lock held.
+func (l *logger) countRepeat(now time.Time, msg []byte) bool {
+	esc := l.escalation
+	r, ok := esc.repeats[string(msg)]
+	if !ok {
+		// Forget expired messages before remembering a new one, so the map does not grow forever.
+		for m, r := range esc.repeats {
+			if now.Sub(r.start) >= esc.window {
+				delete(esc.repeats, m)
+			}
+		}
+		r = &repeat{start: now}
+		esc.repeats[string(msg)] = r
+	} else if now.Sub(r.start) >= esc.window {
+		*r = repeat{start: now}
+	}
+	r.count++
+	if r.escalated || r.count < esc.count {
+		return false
+	}
+	r.escalated = true
+	return true
+}
+
+// escalatedMessage returns the message of the entry logged when msg is escalated.
+func (esc *escalation) escalatedMessage(msg string) string {
+	return msg + " (escalated after " + strconv.Itoa(esc.count) + " repeats within " + esc.window.String() + ")"
+}
@@ -0,0 +1,52 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscalation(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	l.SetTimeFunc(func() time.Time { return now })
+	l.SetEscalation(LevelWarn, LevelError, 3, time.Minute)
+	for i := 0; i < 5; i++ {
+		l.PrintFields(LevelWarn, "Disk almost full", String("disk", "sda"))
+		l.Printf(LevelWarn, "Other warning %d", i)
+		l.Println(LevelInfo, "Disk almost full")
+		now = now.Add(time.Second)
+	}
+	// A new window counts again.
+	now = now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		l.PrintFields(LevelWarn, "Disk almost full", String("disk", "sda"))
+	}
+
+	var escalated []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line[0] == 'E' {
+			escalated = append(escalated, line)
+		}
+	}
+	t.Log("Got: ", escalated)
+	expected := []string{
+		"E/10:00:02 : Disk almost full (escalated after 3 repeats within 1m0s) disk=sda",
+		"E/10:01:05 : Disk almost full (escalated after 3 repeats within 1m0s) disk=sda",
+	}
+	if strings.Join(escalated, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, escalated)
+	}
+
+	// Escalating to a less severe level is rejected.
+	buf.Reset()
+	l.SetEscalation(LevelWarn, LevelInfo, 1, time.Minute)
+	l.Println(LevelWarn, "Disk almost full")
+	if strings.Contains(buf.String(), "escalated") {
+		t.Errorf("Invalid escalation was applied: %s", buf.String())
+	}
+}
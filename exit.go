@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// exitFunc holds the func(code int) terminating the program after fatal entries.
+var exitFunc atomic.Value
+
+// osExit calls the function set by SetExitFunc, os.Exit by default.
+func osExit(code int) {
+	if exit, ok := exitFunc.Load().(func(int)); ok && exit != nil {
+		exit(code)
+		return
+	}
+	os.Exit(code)
+}
+
+// SetExitFunc sets the function called with status 1 after fatal entries, for all loggers. A nil function
+// restores os.Exit. If the function returns, the logging call returns too and the program goes on,
+// which makes fatal paths testable, see CaptureExit.
+func SetExitFunc(exit func(code int)) {
+	exitFunc.Store(exit)
+}
+
+// CapturedExit records the exit requested by fatal entries instead of terminating the program.
+// Use it in tests:
+//
+//	exit := logger.CaptureExit()
+//	defer exit.Restore()
+//	run()
+//	if code, ok := exit.Code(); !ok || code != 1 { ... }
+type CapturedExit struct {
+	code   int
+	called bool
+	sync.Mutex
+}
+
+// CaptureExit sets an exit function recording the exit code, until Restore is called.
+func CaptureExit() *CapturedExit {
+	c := &CapturedExit{}
+	SetExitFunc(func(code int) {
+		c.Lock()
+		defer c.Unlock()
+		c.code, c.called = code, true
+	})
+	return c
+}
+
+// Code returns the last exit code requested, ok is false if no exit was requested.
+func (c *CapturedExit) Code() (code int, ok bool) {
+	c.Lock()
+	defer c.Unlock()
+	return c.code, c.called
+}
+
+// Reset forgets the requested exits.
+func (c *CapturedExit) Reset() {
+	c.Lock()
+	defer c.Unlock()
+	c.code, c.called = 0, false
+}
+
+// Restore sets os.Exit back as exit function.
+func (c *CapturedExit) Restore() {
+	SetExitFunc(nil)
+}
@@ -0,0 +1,78 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCaptureExit(t *testing.T) {
+	exit := CaptureExit()
+	defer exit.Restore()
+	buf := new(bytes.Buffer)
+	l := New(LevelWarn, "", buf, 0)
+	paths := []struct {
+		name  string
+		print func()
+	}{
+		{"Print", func() { l.Print(LevelFatal, "Fatal ", "entry") }},
+		{"Println", func() { l.Println(LevelFatal, "Fatal", "entry") }},
+		{"Printf", func() { l.Printf(LevelFatal, "Fatal %s", "entry") }},
+		{"disabled Println", func() {
+			l.SetLevel(LevelQuiet)
+			defer l.SetLevel(LevelWarn)
+			l.Println(LevelFatal, "Hidden fatal entry")
+		}},
+	}
+	for _, path := range paths {
+		buf.Reset()
+		exit.Reset()
+		path.print()
+		if code, ok := exit.Code(); !ok || code != 1 {
+			t.Errorf("Pattern mismatch for %s,\n\texpected: exit code 1\n\tgot: %d (requested: %v)", path.name, code, ok)
+		}
+		if out := buf.String(); out != "" && out[13:] != "Fatal entry\n" {
+			t.Errorf("Pattern mismatch for %s,\n\texpected: %s\n\tgot: %s", path.name, "Fatal entry", out)
+		}
+	}
+
+	// Other levels and disabled fatal exits request nothing.
+	exit.Reset()
+	l.Println(LevelError, "Not fatal")
+	l.SetFatalExits(false)
+	l.Println(LevelFatal, "Fatal without exit")
+	if code, ok := exit.Code(); ok {
+		t.Errorf("Pattern mismatch,\n\texpected: no exit\n\tgot: exit code %d", code)
+	}
+}
+
+func TestLibraryMode(t *testing.T) {
+	exit := CaptureExit()
+	defer exit.Restore()
+	buf := new(bytes.Buffer)
+	l := New(LevelWarn, "", buf, 0)
+	l.SetLibraryMode(true)
+	for _, print := range []func(){
+		func() { l.Println(LevelFatal, "Fatal entry") },
+		func() { l.Fatalf("Fatal %s", "entry") },
+		func() { l.PrintFields(LevelFatal, "Fatal entry") },
+		func() { tx := l.Begin(); tx.Println(LevelFatal, "Fatal entry") },
+	} {
+		buf.Reset()
+		exit.Reset()
+		print()
+		if code, ok := exit.Code(); ok {
+			t.Errorf("Exit was requested in library mode with code %d", code)
+		}
+		if out := buf.String(); out[:2] != "E/" || out[13:] != "Fatal entry\n" {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "E/... Fatal entry", out)
+		}
+	}
+
+	l.SetLibraryMode(false)
+	l.Println(LevelFatal, "Fatal entry")
+	if _, ok := exit.Code(); !ok {
+		t.Error("Exit was not requested after leaving library mode")
+	}
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// FanOutput configures one output of a FanOutWriter.
+type FanOutput struct {
+	Writer    io.Writer
+	QueueSize int            // Number of writes waiting for the output, 64 if zero or negative.
+	Policy    OverflowPolicy // Which writes are dropped once the queue is full.
+}
+
+// FanOutWriter is an io.Writer copying every write to several outputs. Each output has its own queue
+// drained by its own goroutine, so a slow output, like a network sink, never stalls the others.
+// When the queue of an output is full, writes for it are dropped according to its policy.
+// Write errors of the outputs are not reported, as the writes are asynchronous.
+type FanOutWriter struct {
+	outputs []*fanOutput
+	closed  bool
+	sync.RWMutex
+}
+
+type fanOutput struct {
+	w       io.Writer
+	policy  OverflowPolicy
+	queue   chan fanItem
+	done    chan struct{}
+	dropped uint64
+}
+
+// fanItem is either a write or a flush request, in that case flushed is closed once preceding writes are done.
+type fanItem struct {
+	p       []byte
+	flushed chan struct{}
+}
+
+// NewFanOutWriter returns a FanOutWriter writing to all outputs. Close it when done.
+func NewFanOutWriter(outputs ...FanOutput) *FanOutWriter {
+	w := &FanOutWriter{}
+	for _, o := range outputs {
+		size := o.QueueSize
+		if size <= 0 {
+			size = 64
+		}
+		out := &fanOutput{w: o.Writer, policy: o.Policy, queue: make(chan fanItem, size), done: make(chan struct{})}
+		go out.run()
+		w.outputs = append(w.outputs, out)
+	}
+	return w
+}
+
+func (o *fanOutput) run() {
+	defer close(o.done)
+	for item := range o.queue {
+		if item.flushed != nil {
+			_ = flushOutput(o.w)
+			close(item.flushed)
+			continue
+		}
+		_, _ = o.w.Write(item.p)
+	}
+}
+
+// Write queues a copy of p for every output, it never blocks nor fails, unless the FanOutWriter is closed.
+func (w *FanOutWriter) Write(p []byte) (int, error) {
+	w.RLock()
+	defer w.RUnlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	b := append([]byte(nil), p...)
+	for _, o := range w.outputs {
+		o.push(fanItem{p: b})
+	}
+	return len(p), nil
+}
+
+func (o *fanOutput) push(item fanItem) {
+	for {
+		select {
+		case o.queue <- item:
+			return
+		default:
+		}
+		if o.policy == DropNewest {
+			atomic.AddUint64(&o.dropped, 1)
+			return
+		}
+		select {
+		case old := <-o.queue:
+			if old.flushed != nil {
+				// Never drop flush requests, their writes are done anyway.
+				close(old.flushed)
+			} else {
+				atomic.AddUint64(&o.dropped, 1)
+			}
+		default:
+		}
+	}
+}
+
+// Dropped returns the number of writes dropped so far for each output, in the order they were given.
+func (w *FanOutWriter) Dropped() []uint64 {
+	n := make([]uint64, len(w.outputs))
+	for i, o := range w.outputs {
+		n[i] = atomic.LoadUint64(&o.dropped)
+	}
+	return n
+}
+
+// Flush waits until the queued writes are done and flushes the outputs supporting it.
+func (w *FanOutWriter) Flush() error {
+	w.RLock()
+	if w.closed {
+		w.RUnlock()
+		return nil
+	}
+	var pending []chan struct{}
+	for _, o := range w.outputs {
+		flushed := make(chan struct{})
+		o.queue <- fanItem{flushed: flushed}
+		pending = append(pending, flushed)
+	}
+	w.RUnlock()
+	for _, flushed := range pending {
+		<-flushed
+	}
+	return nil
+}
+
+// Close waits until the queued writes are done and stops the goroutines, the outputs are not closed.
+// Subsequent writes fail with os.ErrClosed.
+func (w *FanOutWriter) Close() error {
+	w.Lock()
+	if w.closed {
+		w.Unlock()
+		return nil
+	}
+	w.closed = true
+	for _, o := range w.outputs {
+		close(o.queue)
+	}
+	w.Unlock()
+	for _, o := range w.outputs {
+		<-o.done
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	buf bytes.Buffer
+	sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
+}
+
+// stalledWriter is like slowWriter, but safe for concurrent use and signals its first write on started.
+type stalledWriter struct {
+	lockedBuffer
+	started, release chan struct{}
+	once             sync.Once
+}
+
+func (w *stalledWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.release
+	return w.lockedBuffer.Write(p)
+}
+
+func TestFanOutWriter(t *testing.T) {
+	tests := []struct {
+		policy   OverflowPolicy
+		expected []int
+	}{
+		{DropNewest, []int{0, 1, 2, 3, 4}},
+		{DropOldest, []int{0, 96, 97, 98, 99}},
+	}
+	for _, test := range tests {
+		fast := &lockedBuffer{}
+		slow := &stalledWriter{started: make(chan struct{}), release: make(chan struct{})}
+		w := NewFanOutWriter(FanOutput{Writer: fast, QueueSize: 100}, FanOutput{Writer: slow, QueueSize: 4, Policy: test.policy})
+		l := New(LevelTrace, "", w, 0)
+		l.Println(LevelInfo, "entry", 0)
+		<-slow.started
+		for i := 1; i < 100; i++ {
+			l.Println(LevelInfo, "entry", i)
+		}
+
+		// The fast output keeps up while the slow one is still blocked on its first write.
+		deadline := time.Now().Add(5 * time.Second)
+		for strings.Count(fast.String(), "\n") < 100 {
+			if time.Now().After(deadline) {
+				t.Fatalf("Fast output fell behind with policy %d: %d entries", test.policy, strings.Count(fast.String(), "\n"))
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if dropped := w.Dropped(); dropped[0] != 0 || dropped[1] != 95 {
+			t.Errorf("Pattern mismatch with policy %d,\n\texpected: [0 95] dropped\n\tgot: %v dropped", test.policy, dropped)
+		}
+
+		close(slow.release)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		var expected string
+		for _, i := range test.expected {
+			expected += fmt.Sprintf("entry %d\n", i)
+		}
+		lines := strings.SplitAfter(slow.String(), "\n")
+		var got string
+		for _, line := range lines {
+			if len(line) > 13 {
+				got += line[13:]
+			}
+		}
+		if got != expected {
+			t.Errorf("Pattern mismatch with policy %d,\n\texpected: %q\n\tgot: %q", test.policy, expected, got)
+		}
+		if _, err := w.Write([]byte("late\n")); err == nil {
+			t.Errorf("Write after Close did not fail")
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+type fieldKind uint8
+
+const (
+	kindString fieldKind = iota
+	kindInt
+	kindBool
+	kindError
+	kindAny
+)
+
+// FieldOrder tells in which order fields of an entry are rendered.
+type FieldOrder int
+
+const (
+	// FieldOrderSorted renders fields sorted by their keys, it is the default for stable output.
+	FieldOrderSorted FieldOrder = iota
+	// FieldOrderInsertion renders fields in the order they were passed.
+	FieldOrderInsertion
+)
+
+// Field is a key/value pair attached to a log entry by ILogger.PrintFields.
+// Use the typed constructors like String or Int to create one, they avoid boxing values into interfaces.
+type Field struct {
+	Key  string
+	kind fieldKind
+	num  int64
+	str  string
+	err  error
+	any  any
+}
+
+// String returns a Field with a string value.
+func String(key, value string) Field {
+	return Field{Key: key, kind: kindString, str: value}
+}
+
+// Int returns a Field with an integer value.
+func Int(key string, value int) Field {
+	return Field{Key: key, kind: kindInt, num: int64(value)}
+}
+
+// Bool returns a Field with a boolean value.
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, kind: kindBool}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+// Err returns a Field with the key "error" holding err. A nil error is rendered as <nil>.
+func Err(err error) Field {
+	return Field{Key: "error", kind: kindError, err: err}
+}
+
+// Any returns a Field with any value. Maps, slices, arrays and structs are rendered as compact JSON,
+// e.g. items=[1,2,3], other values are rendered like fmt.Print does.
+func Any(key string, value any) Field {
+	return Field{Key: key, kind: kindAny, any: value}
+}
+
+// Value returns the value of the Field as an interface.
+func (f Field) Value() any {
+	switch f.kind {
+	case kindInt:
+		return f.num
+	case kindBool:
+		return f.num != 0
+	case kindError:
+		return f.err
+	case kindAny:
+		return f.any
+	}
+	return f.str
+}
+
+// appendValue appends the value of the Field, quoting strings when needed so that entries stay parsable.
+func (f Field) appendValue(buf []byte) []byte {
+	switch f.kind {
+	case kindInt:
+		return strconv.AppendInt(buf, f.num, 10)
+	case kindBool:
+		return strconv.AppendBool(buf, f.num != 0)
+	case kindError:
+		if f.err == nil {
+			return append(buf, "<nil>"...)
+		}
+		return appendString(buf, errorString(f.err))
+	case kindAny:
+		return appendAny(buf, f.any)
+	}
+	return appendString(buf, f.str)
+}
+
+// errorString returns the message of err, or <nil> for a nil error, including a nil pointer whose Error method
+// panics, like fmt prints it.
+func errorString(err error) (s string) {
+	if err == nil {
+		return "<nil>"
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s = nilReceiver(err, r)
+		}
+	}()
+	return err.Error()
+}
+
+// stringerString returns v.String(), or <nil> if v is a nil pointer whose String method panics, like fmt prints it.
+func stringerString(v fmt.Stringer) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = nilReceiver(v, r)
+		}
+	}()
+	return v.String()
+}
+
+// nilReceiver returns <nil> if v is a nil pointer, whose method panicked with r, and panics again otherwise.
+func nilReceiver(v any, r any) string {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return "<nil>"
+	}
+	panic(r)
+}
+
+// appendAny appends composite values as compact JSON and others like fmt.Print does.
+func appendAny(buf []byte, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "<nil>"...)
+	case string:
+		return appendString(buf, v)
+	case []byte:
+		return appendString(buf, string(v))
+	case error:
+		return appendString(buf, errorString(v))
+	case fmt.Stringer:
+		return appendString(buf, stringerString(v))
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if b, err := json.Marshal(v); err == nil {
+			return append(buf, b...)
+		}
+	}
+	return appendString(buf, fmt.Sprint(v))
+}
+
+// appendString appends s as is, or quoted if it is empty or contains spaces, quotes, '=' or non printable characters.
+func appendString(buf []byte, s string) []byte {
+	if needsQuoting(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !strconv.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendFields appends the fields as space separated key=value pairs.
+func appendFields(buf []byte, fields []Field) []byte {
+	for _, f := range fields {
+		buf = append(buf, ' ')
+		buf = append(buf, f.Key...)
+		buf = append(buf, '=')
+		buf = f.appendValue(buf)
+	}
+	return buf
+}
+
+func (l *logger) PrintFields(level Level, msg string, fields ...Field) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
+	}
+	l.handleError(l.printOut(level, fields, func(b *buffer) { *b = append(*b, msg...) }))
+	l.exitIfFatal(level)
+}
+
+// prepareFields returns the fields to be rendered: the fields set by WithFields, at most the maximum number of
+// fields passed, the version field if set, and a fields_truncated=true marker if some were dropped, in the configured
+// order. A key is rendered once: the fields passed override the ones set by WithFields and the version, and among
+// those, the last one wins. Changes are done on a copy reused across entries, so the caller's slice is left untouched.
+// It must be called with the lock held.
+func (l *logger) prepareFields(fields []Field) []Field {
+	truncated := l.maxFields > 0 && len(fields) > l.maxFields
+	if truncated {
+		fields = fields[:l.maxFields]
+	}
+	extra := len(l.fields)
+	if l.version != "" {
+		extra++
+	}
+	sorted := l.fieldOrder == FieldOrderSorted && len(fields)+extra > 1
+	if !truncated && !sorted && extra == 0 && !hasDuplicateKeys(fields) {
+		return fields
+	}
+	prepared := append(append(l.fieldBuf[:0], l.fields...), fields...)
+	if l.version != "" && !hasKey(fields, "version") {
+		prepared = append(prepared, String("version", l.version))
+	}
+	prepared = dedupFields(prepared)
+	if sorted {
+		// Insertion sort: stable, allocation free and fast for the few fields an entry usually has.
+		for i := 1; i < len(prepared); i++ {
+			for j := i; j > 0 && prepared[j].Key < prepared[j-1].Key; j-- {
+				prepared[j], prepared[j-1] = prepared[j-1], prepared[j]
+			}
+		}
+	}
+	if truncated {
+		prepared = append(prepared, Bool("fields_truncated", true))
+	}
+	l.fieldBuf = prepared
+	return prepared
+}
+
+// hasKey tells whether one of fields has the key.
+func hasKey(fields []Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDuplicateKeys tells whether two of fields have the same key.
+func hasDuplicateKeys(fields []Field) bool {
+	for i := 1; i < len(fields); i++ {
+		if hasKey(fields[:i], fields[i].Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupFields removes in place the fields whose key is used by a later one, keeping the order of the others.
+// Entries have few fields, so the quadratic scan is cheaper than a map.
+func dedupFields(fields []Field) []Field {
+	n := 0
+	for i, f := range fields {
+		if !hasKey(fields[i+1:], f.Key) {
+			fields[n] = f
+			n++
+		}
+	}
+	return fields[:n]
+}
+
+func (l *logger) WithFields(fields ...Field) ILogger {
+	l.Lock()
+	defer l.Unlock()
+	// Copy the state directly rather than through Clone: children are often short lived, e.g. per request, so they
+	// share the clock and the terminal check, and run no background goroutines like the drop summary.
+	child := &logger{
+		level:         atomic.LoadInt32(&l.level),
+		prefix:        l.prefix,
+		prefixTime:    l.prefixTime,
+		flags:         l.flags,
+		out:           l.out,
+		terminal:      l.terminal,
+		colors:        l.colors,
+		colorRules:    l.colorRules[:len(l.colorRules):len(l.colorRules)],
+		bodySep:       l.bodySep,
+		newline:       l.newline,
+		maxLineLen:    l.maxLineLen,
+		fieldOrder:    l.fieldOrder,
+		maxFields:     l.maxFields,
+		fields:        append(l.fields[:len(l.fields):len(l.fields)], fields...),
+		version:       l.version,
+		idGen:         l.idGen,
+		fatalExits:    atomic.LoadInt32(&l.fatalExits),
+		libraryMode:   atomic.LoadInt32(&l.libraryMode),
+		repanic:       atomic.LoadInt32(&l.repanic),
+		stackWindow:   l.stackWindow,
+		onError:       l.onError,
+		hooks:         l.hooks[:len(l.hooks):len(l.hooks)],
+		quiet:         l.quiet[:len(l.quiet):len(l.quiet)],
+		extractors:    l.extractors[:len(l.extractors):len(l.extractors)],
+		writeTimeout:  l.writeTimeout,
+		retryAttempts: l.retryAttempts,
+		retryBackoff:  l.retryBackoff,
+		now:           l.now,
+	}
+	if len(l.labels) > 0 {
+		child.labels = make(map[Level]string, len(l.labels))
+		for k, v := range l.labels {
+			child.labels[k] = v
+		}
+	}
+	if len(l.styles) > 0 {
+		child.styles = make(map[Level]levelStyle, len(l.styles))
+		for k, v := range l.styles {
+			child.styles[k] = v
+		}
+	}
+	if len(l.levelOut) > 0 {
+		child.levelOut = make(map[Level]io.Writer, len(l.levelOut))
+		for k, v := range l.levelOut {
+			child.levelOut[k] = v
+		}
+	}
+	if esc := l.escalation; esc != nil {
+		child.escalation = &escalation{from: esc.from, to: esc.to, count: esc.count, window: esc.window, repeats: make(map[string]*repeat)}
+	}
+	return child
+}
+
+func (l *logger) SetVersion(v string) {
+	l.Lock()
+	defer l.Unlock()
+	l.version = v
+}
+
+func (l *logger) SetMaxFields(n int) {
+	l.Lock()
+	defer l.Unlock()
+	l.maxFields = n
+}
+
+func (l *logger) SetFieldOrder(order FieldOrder) {
+	l.Lock()
+	defer l.Unlock()
+	l.fieldOrder = order
+}
+
+func (l *logger) PrintCoded(level Level, code string, msg string, v ...any) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
+	}
+	l.handleError(l.printOut(level, []Field{String("code", code)}, func(b *buffer) { fmt.Fprintf(b, msg, v...) }))
+	l.exitIfFatal(level)
+}
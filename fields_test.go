@@ -0,0 +1,285 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPrintFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	tests := []struct {
+		field    Field
+		expected string
+	}{
+		{String("user", "alice"), "user=alice"},
+		{String("name", "Alice Smith"), `name="Alice Smith"`},
+		{String("empty", ""), `empty=""`},
+		{Int("count", -42), "count=-42"},
+		{Bool("ok", true), "ok=true"},
+		{Bool("ok", false), "ok=false"},
+		{Err(errors.New("disk full")), `error="disk full"`},
+		{Err(nil), "error=<nil>"},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		l.PrintFields(LevelInfo, "Fields", test.field)
+		out := buf.String()
+		out = out[13 : len(out)-1]
+		expected := "Fields " + test.expected
+		t.Log("Got: ", out)
+		if out != expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+		}
+	}
+
+	buf.Reset()
+	l.PrintFields(LevelInfo, "Multiple", String("a", "1"), Int("b", 2), Bool("c", true))
+	out := buf.String()
+	out = out[13 : len(out)-1]
+	expected := "Multiple a=1 b=2 c=true"
+	t.Log("Got: ", out)
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	e := errors.New("boom")
+	for _, test := range []struct {
+		field    Field
+		expected any
+	}{
+		{String("k", "v"), "v"},
+		{Int("k", 7), int64(7)},
+		{Bool("k", true), true},
+		{Err(e), e},
+	} {
+		if v := test.field.Value(); v != test.expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", test.expected, v)
+		}
+	}
+}
+
+func TestFieldOrder(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	fields := []Field{String("zone", "eu"), Int("id", 3), Bool("admin", false)}
+	for order, expected := range map[FieldOrder]string{
+		FieldOrderSorted:    "Ordered admin=false id=3 zone=eu",
+		FieldOrderInsertion: "Ordered zone=eu id=3 admin=false",
+	} {
+		buf.Reset()
+		l.SetFieldOrder(order)
+		l.PrintFields(LevelInfo, "Ordered", fields...)
+		out := buf.String()
+		out = out[13 : len(out)-1]
+		t.Log("Got: ", out)
+		if out != expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+		}
+	}
+	if fields[0].Key != "zone" {
+		t.Errorf("Sorting modified the passed fields: %v", fields)
+	}
+}
+
+func TestPrintCoded(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.PrintCoded(LevelError, "E1042", "Payment %s failed after %d attempts", "#77", 3)
+	out := buf.String()
+	out = out[13 : len(out)-1]
+	expected := "Payment #77 failed after 3 attempts code=E1042"
+	t.Log("Got: ", out)
+	if buf.String()[:2] != "E/" || out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+}
+
+func TestCompositeFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	tests := []struct {
+		field    Field
+		expected string
+	}{
+		{Any("items", []int{1, 2, 3}), "items=[1,2,3]"},
+		{Any("tags", map[string]int{"b": 2, "a": 1}), `tags={"a":1,"b":2}`},
+		{Any("point", &struct{ X, Y int }{1, 2}), `point={"X":1,"Y":2}`},
+		{Any("empty", []string{}), "empty=[]"},
+		{Any("num", 3.5), "num=3.5"},
+		{Any("text", "two words"), `text="two words"`},
+		{Any("nothing", nil), "nothing=<nil>"},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		l.PrintFields(LevelInfo, "Any", test.field)
+		out := buf.String()
+		out = out[13 : len(out)-1]
+		expected := "Any " + test.expected
+		t.Log("Got: ", out)
+		if out != expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+		}
+	}
+}
+
+func TestMaxFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetMaxFields(2)
+	fields := []Field{String("c", "3"), String("a", "1"), String("b", "2"), String("d", "4")}
+	l.PrintFields(LevelInfo, "Capped", fields...)
+	out := buf.String()
+	out = out[13 : len(out)-1]
+	expected := "Capped a=1 c=3 fields_truncated=true"
+	t.Log("Got: ", out)
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+
+	buf.Reset()
+	l.PrintFields(LevelInfo, "Not capped", fields[:2]...)
+	out = buf.String()
+	out = out[13 : len(out)-1]
+	expected = "Not capped a=1 c=3"
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+	if len(fields) != 4 || fields[1].Key != "a" {
+		t.Errorf("Capping modified the passed fields: %v", fields)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	l.SetVersion("1.4.2")
+	var fired []*Entry
+	l.AddHook(hookFunc(func(e *Entry) { fired = append(fired, e) }))
+	l.Println(LevelInfo, "Started")
+	l.PrintFields(LevelWarn, "Slow", String("z", "last"), Int("ms", 300))
+	l.Clone().Print(LevelError, "Cloned")
+	expected := "I/10:00:00 : Started version=1.4.2\nW/10:00:00 : Slow ms=300 version=1.4.2 z=last\nE/10:00:00 : Cloned version=1.4.2\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+	if len(fired) == 0 || fired[0].Fields[0].Value() != "1.4.2" {
+		t.Errorf("Hook did not get the version field: %+v", fired)
+	}
+
+	buf.Reset()
+	l.SetVersion("")
+	l.Println(LevelInfo, "Unversioned")
+	if got := buf.String(); got != "I/10:00:00 : Unversioned\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "I/10:00:00 : Unversioned\n", got)
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	child := l.WithFields(String("component", "db"))
+	grandchild := child.WithFields(Int("shard", 2))
+	child.PrintFields(LevelInfo, "Child", String("a", "1"))
+	grandchild.Println(LevelInfo, "Grandchild")
+	l.Println(LevelInfo, "Parent")
+	expected := "I/10:00:00 : Child a=1 component=db\nI/10:00:00 : Grandchild component=db shard=2\nI/10:00:00 : Parent\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+}
+
+func TestDuplicateFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	l.SetVersion("1.4.2")
+	child := l.WithFields(String("component", "db"), Int("shard", 1)).WithFields(Int("shard", 2))
+	child.PrintFields(LevelInfo, "Overridden", String("component", "cache"), String("version", "2.0.0"))
+	child.PrintFields(LevelInfo, "Repeated", Int("n", 1), Int("n", 2))
+	child.SetFieldOrder(FieldOrderInsertion)
+	child.PrintFields(LevelInfo, "Ordered", String("component", "cache"), Int("n", 3))
+	expected := "I/10:00:00 : Overridden component=cache shard=2 version=2.0.0\n" +
+		"I/10:00:00 : Repeated component=db n=2 shard=2 version=1.4.2\n" +
+		"I/10:00:00 : Ordered shard=2 component=cache n=3 version=1.4.2\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+
+	buf.Reset()
+	l.SetVersion("")
+	l.SetFieldOrder(FieldOrderInsertion)
+	l.PrintFields(LevelInfo, "Plain", Int("n", 1), Int("n", 2))
+	if got := buf.String(); got != "I/10:00:00 : Plain n=2\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "I/10:00:00 : Plain n=2\n", got)
+	}
+}
+
+// nilError is an error whose Error method dereferences its receiver.
+type nilError struct{ msg string }
+
+func (e *nilError) Error() string { return e.msg }
+
+func TestNilPointerError(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	l.PrintFields(LevelError, "Failed", Err((*nilError)(nil)))
+	if got := buf.String(); got != "E/10:00:00 : Failed error=<nil>\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "E/10:00:00 : Failed error=<nil>\n", got)
+	}
+
+	rec := new(bytes.Buffer)
+	pw := NewProtoWriter(rec)
+	if err := pw.WriteEntry(&Entry{Level: LevelError, Message: "Failed", Fields: []Field{Err((*nilError)(nil))}}); err != nil {
+		t.Fatal(err)
+	}
+	e, err := NewProtoReader(rec).ReadEntry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Fields) != 1 || e.Fields[0].Value() != "<nil>" {
+		t.Errorf("Pattern mismatch,\n\texpected: error=<nil>\n\tgot: %+v", e.Fields)
+	}
+}
+
+// nilStringer is a fmt.Stringer whose String method dereferences its receiver.
+type nilStringer struct{ name string }
+
+func (s *nilStringer) String() string { return s.name }
+
+func TestNilPointerAny(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	l.PrintFields(LevelInfo, "Nil", Any("stringer", (*nilStringer)(nil)), Any("error", (*nilError)(nil)))
+	l.Println(LevelInfo, (*nilStringer)(nil))
+	expected := "I/10:00:00 : Nil error=<nil> stringer=<nil>\nI/10:00:00 : <nil>\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+}
+
+func TestWithFieldsGoroutines(t *testing.T) {
+	l := New(LevelTrace, "", new(bytes.Buffer), 0)
+	l.SetCoarseTime(time.Millisecond)
+	l.SetDropSummary(time.Minute)
+	defer l.Close()
+	// Children are created per request, they must not start nor stop background goroutines.
+	n := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		l.WithFields(Int("i", i)).Println(LevelInfo, "Child")
+	}
+	if got := runtime.NumGoroutine(); got != n {
+		t.Errorf("Pattern mismatch,\n\texpected: %d goroutines\n\tgot: %d", n, got)
+	}
+}
@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// openLogFile opens the file at path for appending, creating it if needed.
+func openLogFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
+
+// isEmptyFile tells whether f has no content yet, so that a header should be written first.
+func isEmptyFile(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Size() == 0
+}
+
+// NewFileLogger returns a logger appending to the file at path, with no prefix.
+// Missing parent directories are created. ILogger.Close closes the file.
+func NewFileLogger(path string, level Level, flags int) (ILogger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l := New(level, "", f, flags)
+	l.(*logger).closer = f
+	return l, nil
+}
@@ -0,0 +1,39 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewFileLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "dirs", "app.log")
+	l, err := NewFileLogger(path, LevelInfo, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Println(LevelInfo, "In a nested file")
+	if f, ok := l.GetOutput().(*os.File); ok {
+		_ = f.Close()
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := string(b); len(out) < 13 || out[13:] != "In a nested file\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "In a nested file\n", out)
+	}
+
+	// Appends to the existing file.
+	l, err = NewFileLogger(path, LevelInfo, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Println(LevelInfo, "Appended")
+	_ = l.GetOutput().(*os.File).Close()
+	if b, _ = os.ReadFile(path); len(b) != 2*13+len("In a nested file\nAppended\n") {
+		t.Errorf("File was not appended: %s", b)
+	}
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+)
+
+// flagNames are the names of the flags accepted by SetFlagsFromString, in the order of their values.
+var flagNames = []struct {
+	name string
+	flag int
+}{
+	{"color", FlagColorMode},
+	{"numeric", FlagNumericLevel},
+	{"indent", FlagIndentMultiline},
+	{"autocolor", FlagAutoColor},
+	{"sequence", FlagSequence},
+	{"normalize", FlagNormalizeNewlines},
+	{"delta", FlagDeltaTime},
+	{"trim", FlagTrimNewlines},
+	{"package", FlagPackage},
+}
+
+// parseFlags parses comma-separated flag names like "color,indent", case-insensitively.
+// Spaces around names and empty names are ignored, so an empty string means no flags.
+func parseFlags(s string) (int, error) {
+	flags := 0
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, f := range flagNames {
+			if f.name == name {
+				flags |= f.flag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, errors.New("logger: unknown flag " + name)
+		}
+	}
+	return flags, nil
+}
+
+func (l *logger) SetFlagsFromString(s string) error {
+	flags, err := parseFlags(s)
+	if err != nil {
+		return err
+	}
+	l.SetFlags(flags)
+	return nil
+}
+
+// flagsString returns the comma-separated names of flags, in the order of their values.
+// Unnamed bits are ignored.
+func flagsString(flags int) string {
+	var names []string
+	for _, f := range flagNames {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func (l *logger) FlagsString() string {
+	return flagsString(l.GetFlags())
+}
@@ -0,0 +1,42 @@
+package logger
+
+import "testing"
+
+func TestSetFlagsFromString(t *testing.T) {
+	l := New(LevelTrace, "", nil, FlagNumericLevel)
+	tests := []struct {
+		s        string
+		expected int
+		valid    bool
+	}{
+		{"color,indent", FlagColorMode | FlagIndentMultiline, true},
+		{" AutoColor , sequence,normalize ", FlagAutoColor | FlagSequence | FlagNormalizeNewlines, true},
+		{"", 0, true},
+		{"numeric,", FlagNumericLevel, true},
+		{"color,microseconds", FlagNumericLevel, false},
+	}
+	for _, test := range tests {
+		err := l.SetFlagsFromString(test.s)
+		if (err == nil) != test.valid || l.GetFlags() != test.expected {
+			t.Errorf("Pattern mismatch for %q,\n\texpected: %d (valid: %v)\n\tgot: %d (%v)", test.s, test.expected, test.valid, l.GetFlags(), err)
+		}
+	}
+}
+
+func TestFlagsString(t *testing.T) {
+	l := New(LevelTrace, "", nil, 0)
+	if s := l.FlagsString(); s != "" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "", s)
+	}
+	for flags := 0; flags < FlagPackage<<1; flags++ {
+		l.SetFlags(flags)
+		s := l.FlagsString()
+		if err := l.SetFlagsFromString(s); err != nil || l.GetFlags() != flags {
+			t.Errorf("Pattern mismatch for %q,\n\texpected: %d\n\tgot: %d (%v)", s, flags, l.GetFlags(), err)
+		}
+	}
+	l.SetFlags(FlagSequence | FlagColorMode | 1<<20)
+	if s := l.FlagsString(); s != "color,sequence" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "color,sequence", s)
+	}
+}
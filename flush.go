@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+)
+
+type flusher interface {
+	Flush() error
+}
+
+type syncer interface {
+	Sync() error
+}
+
+// flushOutput flushes buffered writers like bufio.Writer and syncs files to disk.
+// Only regular files are synced, terminals and pipes do not support it.
+func flushOutput(out io.Writer) error {
+	switch w := out.(type) {
+	case flusher:
+		return w.Flush()
+	case *os.File:
+		if info, err := w.Stat(); err == nil && info.Mode().IsRegular() {
+			return w.Sync()
+		}
+	case syncer:
+		return w.Sync()
+	}
+	return nil
+}
+
+func (l *logger) Flush() error {
+	l.Lock()
+	defer l.Unlock()
+	return flushOutput(l.out)
+}
+
+func (l *logger) PrintSync(level Level, v ...any) error {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return nil
+	}
+	err := l.printOut(level, nil, func(b *buffer) { fmt.Fprint(b, v...) })
+	if err == nil {
+		err = l.flushLevel(level)
+	}
+	l.exitIfFatal(level)
+	return err
+}
+
+// flushLevel flushes the output entries of level are written to.
+func (l *logger) flushLevel(level Level) error {
+	l.Lock()
+	defer l.Unlock()
+	if out, ok := l.levelOut[level]; ok {
+		return flushOutput(out)
+	}
+	return flushOutput(l.out)
+}
+
+func (l *logger) FlushOnSignals(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, signals...)
+	go func() {
+		select {
+		case sig := <-ch:
+			l.handleError(l.closeWithin(shutdownTimeout))
+			// Restore the default behavior and deliver the signal again.
+			signal.Stop(ch)
+			if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+				return
+			}
+			os.Exit(2)
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
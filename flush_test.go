@@ -0,0 +1,122 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFlush(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := bufio.NewWriter(buf)
+	l := New(LevelTrace, "", w, 0)
+	l.Println(LevelInfo, "Buffered")
+	if buf.Len() != 0 {
+		t.Fatalf("Entry was not buffered: %s", buf.String())
+	}
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); out[13:] != "Buffered\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "Buffered\n", out)
+	}
+	stop := l.FlushOnSignals()
+	stop()
+	stop()
+	if err := New(LevelTrace, "", nil, 0).Flush(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFlushDefault(t *testing.T) {
+	l := GetDefault()
+	out, level := l.GetOutput(), l.GetLevel()
+	defer func() {
+		l.SetOutput(out)
+		l.SetLevel(level)
+	}()
+
+	buf := new(bytes.Buffer)
+	l.SetOutput(bufio.NewWriter(buf))
+	l.SetLevel(LevelInfo)
+	Println(LevelInfo, "Buffered default entry")
+	if buf.Len() != 0 {
+		t.Fatalf("Entry was not buffered: %s", buf.String())
+	}
+	if err := FlushDefault(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got[13:] != "Buffered default entry\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "Buffered default entry\n", got)
+	}
+}
+
+// syncWriter records the calls it receives, its Sync fails if err is set.
+type syncWriter struct {
+	calls []string
+	err   error
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.calls = append(w.calls, "write "+string(p[13:len(p)-1]))
+	return len(p), nil
+}
+
+func (w *syncWriter) Sync() error {
+	w.calls = append(w.calls, "sync")
+	return w.err
+}
+
+func TestPrintSync(t *testing.T) {
+	out, routed := new(syncWriter), new(syncWriter)
+	l := New(LevelInfo, "", out, 0)
+	l.SetLevelOutput(LevelError, routed)
+	if err := l.PrintSync(LevelInfo, "Durable"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.PrintSync(LevelDebug, "Disabled"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(out.calls, ", "); got != "write Durable, sync" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "write Durable, sync", got)
+	}
+	routed.err = errors.New("disk full")
+	if err := l.PrintSync(LevelError, "Routed"); err != routed.err {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", routed.err, err)
+	}
+	if got := strings.Join(routed.calls, ", "); got != "write Routed, sync" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "write Routed, sync", got)
+	}
+}
+
+func TestFlushPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	l := New(LevelTrace, "", w, 0)
+	l.Println(LevelInfo, "Piped")
+	if err := l.Flush(); err != nil {
+		t.Errorf("Flushing a pipe failed: %v", err)
+	}
+	if err := l.PrintSync(LevelInfo, "Piped"); err != nil {
+		t.Errorf("Flushing a pipe failed: %v", err)
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "flush.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := New(LevelTrace, "", f, 0).PrintSync(LevelInfo, "Synced"); err != nil {
+		t.Errorf("Syncing a file failed: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+//go:build !windows && !plan9 && !nolog
+
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fileHook creates a file when it is closed.
+type fileHook string
+
+func (h fileHook) Fire(*Entry) {}
+
+func (h fileHook) Close() error {
+	return os.WriteFile(string(h), []byte("Closed\n"), 0644)
+}
+
+// TestFlushOnSignals runs itself in a child process, which buffers an entry and receives SIGINT.
+func TestFlushOnSignals(t *testing.T) {
+	if path := os.Getenv("GOLOGGER_SIGNAL_FILE"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			os.Exit(3)
+		}
+		l := New(LevelTrace, "", bufio.NewWriter(f), 0)
+		l.AddHook(fileHook(path + ".hook"))
+		l.FlushOnSignals(os.Interrupt)
+		l.Println(LevelInfo, "Flushed on SIGINT")
+		_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+		time.Sleep(5 * time.Second)
+		os.Exit(0)
+	}
+
+	path := filepath.Join(t.TempDir(), "signal.log")
+	cmd := exec.Command(os.Args[0], "-test.run", "^TestFlushOnSignals$")
+	cmd.Env = append(os.Environ(), "GOLOGGER_SIGNAL_FILE="+path)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Child process was not terminated by the signal: %v", err)
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); !ok || !status.Signaled() || status.Signal() != syscall.SIGINT {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", syscall.SIGINT, exitErr)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := string(b); len(out) < 13 || out[13:] != "Flushed on SIGINT\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "Flushed on SIGINT\n", out)
+	}
+	if _, err := os.Stat(path + ".hook"); err != nil {
+		t.Errorf("Hook was not closed before the signal was delivered again: %v", err)
+	}
+}
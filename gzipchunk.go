@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrInvalidIndex is returned by ExtractGzipRange when a line of the index can not be parsed.
+var ErrInvalidIndex = errors.New("logger: invalid gzip chunk index")
+
+// ChunkedGzipWriter is an io.Writer compressing entries into a gzip file made of chunks, each one a gzip member
+// which can be decompressed on its own. The file is still a valid gzip file, readable by gzip -d or zcat.
+// Every chunk is recorded in an index file, with its offset, its size and the time range of its entries,
+// so that ExtractGzipRange can decompress only the chunks of a time range.
+// A chunk is finished once it holds a number of uncompressed bytes, by Flush, which ILogger.Flush calls, and by Close.
+type ChunkedGzipWriter struct {
+	file      *os.File
+	index     *os.File
+	chunkSize int
+	now       func() time.Time
+	out       offsetWriter
+	gz        *gzip.Writer
+	// active tells whether a chunk is started: at offset, with raw uncompressed bytes written from start to end.
+	active     bool
+	offset     int64
+	raw        int
+	start, end time.Time
+	sync.Mutex
+}
+
+// offsetWriter counts the bytes written to w, to know the offset of the next chunk.
+type offsetWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.Write(p)
+	o.n += int64(n)
+	return n, err
+}
+
+// NewChunkedGzipWriter opens (or creates) the gzip file at path and its index at path+".idx" in append mode,
+// finishing chunks once they hold chunkSize uncompressed bytes. Close it when done, the last chunk is lost otherwise.
+func NewChunkedGzipWriter(path string, chunkSize int) (*ChunkedGzipWriter, error) {
+	f, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	index, err := openLogFile(path + ".idx")
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &ChunkedGzipWriter{
+		file:      f,
+		index:     index,
+		chunkSize: chunkSize,
+		now:       time.Now,
+		out:       offsetWriter{w: f, n: info.Size()},
+	}, nil
+}
+
+// SetTimeFunc sets the function returning the time recorded for every write, passing nil restores time.Now.
+// The logger should use the same one, see ILogger.SetTimeFunc.
+func (w *ChunkedGzipWriter) SetTimeFunc(now func() time.Time) {
+	w.Lock()
+	defer w.Unlock()
+	if now == nil {
+		now = time.Now
+	}
+	w.now = now
+}
+
+// Write compresses p into the current chunk, starting one if needed, and finishes the chunk if it is full.
+// A single write is never split across chunks.
+func (w *ChunkedGzipWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	now := w.now()
+	if !w.active {
+		if w.gz == nil {
+			w.gz = gzip.NewWriter(&w.out)
+		} else {
+			w.gz.Reset(&w.out)
+		}
+		w.active, w.offset, w.raw, w.start = true, w.out.n, 0, now
+	}
+	if _, err := w.gz.Write(p); err != nil {
+		return 0, err
+	}
+	w.raw += len(p)
+	w.end = now
+	if w.raw >= w.chunkSize {
+		if err := w.finishChunk(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// finishChunk writes the end of the current chunk and records it in the index. It must be called with the lock held.
+func (w *ChunkedGzipWriter) finishChunk() error {
+	if !w.active {
+		return nil
+	}
+	w.active = false
+	if err := w.gz.Close(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w.index, "%d %d %d %d\n", w.offset, w.out.n-w.offset, w.start.UnixNano(), w.end.UnixNano())
+	return err
+}
+
+// Flush finishes the current chunk, so that everything written so far can be extracted.
+func (w *ChunkedGzipWriter) Flush() error {
+	w.Lock()
+	defer w.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.finishChunk()
+}
+
+// Close finishes the current chunk and closes the files, subsequent writes fail with os.ErrClosed.
+func (w *ChunkedGzipWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.finishChunk()
+	for _, f := range []*os.File{w.file, w.index} {
+		if e := f.Close(); err == nil {
+			err = e
+		}
+	}
+	w.file, w.index = nil, nil
+	return err
+}
+
+// ExtractGzipRange decompresses to out the chunks of the file at path, written by a ChunkedGzipWriter,
+// which hold entries written from from to to, both included. Whole chunks are extracted, so entries
+// around the range may be included too. The index at path+".idx" tells where the chunks are.
+func ExtractGzipRange(path string, from, to time.Time, out io.Writer) error {
+	index, err := os.Open(path + ".idx")
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(index)
+	for scanner.Scan() {
+		var offset, size, start, end int64
+		if n, _ := fmt.Sscanf(scanner.Text(), "%d %d %d %d", &offset, &size, &start, &end); n != 4 {
+			return ErrInvalidIndex
+		}
+		if time.Unix(0, end).Before(from) || time.Unix(0, start).After(to) {
+			continue
+		}
+		gz, err := gzip.NewReader(io.NewSectionReader(f, offset, size))
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, gz); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,65 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChunkedGzipWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.log.gz")
+	w, err := NewChunkedGzipWriter(path, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	clock := func() time.Time { return now }
+	w.SetTimeFunc(clock)
+	l := New(LevelTrace, "", w, 0)
+	l.SetTimeFunc(clock)
+	// Every entry is 21 bytes long, so a chunk is finished on its third one: one chunk per minute.
+	var all string
+	for i := 0; i < 9; i++ {
+		now = time.Date(2022, 1, 1, 10, i/3, i%3, 0, time.Local)
+		l.Printf(LevelInfo, "entry %d", i)
+		all += fmt.Sprintf("I/10:%02d:%02d : entry %d\n", i/3, i%3, i)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(readFile(t, path+".idx"), "\n"); n != 3 {
+		t.Errorf("Pattern mismatch,\n\texpected: 3 chunks\n\tgot: %d", n)
+	}
+
+	// The whole file is a valid gzip file.
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(gz); string(b) != all {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", all, b)
+	}
+
+	out := new(bytes.Buffer)
+	from, to := time.Date(2022, 1, 1, 10, 1, 0, 0, time.Local), time.Date(2022, 1, 1, 10, 1, 30, 0, time.Local)
+	if err := ExtractGzipRange(path, from, to, out); err != nil {
+		t.Fatal(err)
+	}
+	expected := "I/10:01:00 : entry 3\nI/10:01:01 : entry 4\nI/10:01:02 : entry 5\n"
+	if got := out.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// hashMarker separates a record from its hash in the output of a HashChainWriter.
+const hashMarker = " sha256="
+
+// ErrHashChainBroken is returned by VerifyHashChain when a record was modified, removed or inserted.
+var ErrHashChainBroken = errors.New("logger: hash chain broken")
+
+// HashChainWriter is an io.Writer making audit logs tamper-evident. Every write, i.e. every entry, is followed
+// by " sha256=<hex>", the hash of the previous hash and the entry, so that modifying, inserting or removing
+// an entry breaks the chain checked by VerifyHashChain. Removing the last entries can only be detected
+// by comparing the last hash with one kept elsewhere, see LastHash.
+type HashChainWriter struct {
+	w    io.Writer
+	prev [sha256.Size]byte
+	buf  []byte
+	sync.Mutex
+}
+
+// NewHashChainWriter returns a HashChainWriter writing to w, starting a new chain.
+func NewHashChainWriter(w io.Writer) *HashChainWriter {
+	return &HashChainWriter{w: w}
+}
+
+// chainHash returns the hash of a record following the record with the prev hash.
+func chainHash(prev [sha256.Size]byte, record []byte) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write(prev[:])
+	h.Write(record)
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// Write writes p without its trailing newline, followed by its chained hash and a newline.
+func (w *HashChainWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	record := bytes.TrimSuffix(p, []byte("\n"))
+	sum := chainHash(w.prev, record)
+	w.buf = append(append(w.buf[:0], record...), hashMarker...)
+	var sumHex [2 * sha256.Size]byte
+	hex.Encode(sumHex[:], sum[:])
+	w.buf = append(w.buf, sumHex[:]...)
+	w.buf = append(w.buf, '\n')
+	if _, err := w.w.Write(w.buf); err != nil {
+		return 0, err
+	}
+	w.prev = sum
+	return len(p), nil
+}
+
+// LastHash returns the hex encoded hash of the last record written, or an empty string if there is none.
+func (w *HashChainWriter) LastHash() string {
+	w.Lock()
+	defer w.Unlock()
+	return hashString(w.prev)
+}
+
+// hashString returns the hex encoded sum, or an empty string if it is the zero hash starting a chain.
+func hashString(sum [sha256.Size]byte) string {
+	if sum == [sha256.Size]byte{} {
+		return ""
+	}
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyHashChain checks the records written by a HashChainWriter and returns their number, with the
+// hex encoded hash of the last one. Records may span several lines. If a record does not match its hash,
+// ErrHashChainBroken is returned, wrapped with the number of the line where the chain breaks.
+func VerifyHashChain(r io.Reader) (records int, last string, err error) {
+	var prev [sha256.Size]byte
+	var record []byte
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxRecordLen)
+	for line := 1; sc.Scan(); line++ {
+		b := sc.Bytes()
+		i := bytes.LastIndex(b, []byte(hashMarker))
+		if i < 0 || len(b)-i-len(hashMarker) != 2*sha256.Size {
+			// A line of a multi-line record, the hash comes on its last line.
+			record = append(append(record, b...), '\n')
+			continue
+		}
+		record = append(record, b[:i]...)
+		var sum [sha256.Size]byte
+		if _, e := hex.Decode(sum[:], b[i+len(hashMarker):]); e != nil || sum != chainHash(prev, record) {
+			return records, hashString(prev), wrapLineError(ErrHashChainBroken, line)
+		}
+		prev, record = sum, record[:0]
+		records++
+	}
+	if err = sc.Err(); err != nil {
+		return records, hashString(prev), err
+	}
+	if len(record) > 0 {
+		return records, hashString(prev), ErrHashChainBroken
+	}
+	return records, hashString(prev), nil
+}
+
+// lineError is an error which happened at a line of an input.
+type lineError struct {
+	err  error
+	line int
+}
+
+func wrapLineError(err error, line int) error {
+	return &lineError{err: err, line: line}
+}
+
+func (e *lineError) Error() string {
+	return e.err.Error() + " at line " + strconv.Itoa(e.line)
+}
+
+func (e *lineError) Unwrap() error {
+	return e.err
+}
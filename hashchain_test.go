@@ -0,0 +1,44 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashChainWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewHashChainWriter(buf)
+	l := New(LevelTrace, "AUDIT", w, 0)
+	l.Println(LevelInfo, "user=alice action=login")
+	l.Println(LevelInfo, "user=alice action=transfer\namount=100")
+	l.Println(LevelInfo, "user=alice action=logout")
+	out := buf.String()
+	t.Log("Got: ", out)
+
+	records, last, err := VerifyHashChain(strings.NewReader(out))
+	if err != nil || records != 3 || last != w.LastHash() {
+		t.Fatalf("Pattern mismatch,\n\texpected: 3 records ending with %s\n\tgot: %d records ending with %s (%v)", w.LastHash(), records, last, err)
+	}
+
+	lines := strings.SplitAfter(out, "\n")
+	tampered := []string{
+		strings.Replace(out, "amount=100", "amount=900", 1),
+		lines[0] + lines[3],
+		lines[0] + lines[0] + lines[1] + lines[2] + lines[3],
+		out[:len(out)-len(lines[3])-10] + "\n",
+	}
+	for _, data := range tampered {
+		if _, _, err := VerifyHashChain(strings.NewReader(data)); !errors.Is(err, ErrHashChainBroken) {
+			t.Errorf("Tampering was not detected in %q: %v", data, err)
+		}
+	}
+
+	// Removing the last records keeps a valid chain, but its last hash differs.
+	if _, last, err = VerifyHashChain(strings.NewReader(lines[0])); err != nil || last == w.LastHash() {
+		t.Errorf("Truncated chain was not detected by its last hash: %v", err)
+	}
+}
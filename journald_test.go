@@ -0,0 +1,68 @@
+//go:build linux && !nolog
+
+package logger
+
+import (
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournaldWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	w, err := NewJournaldWriter(path, "gologger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	l := New(LevelTrace, "", w, 0)
+
+	read := func() string {
+		b := make([]byte, 4096)
+		n, err := server.Read(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b[:n])
+	}
+
+	l.PrintFields(LevelError, "Disk is full", String("mount", "/var"), Int("free-bytes", 0))
+	out := read()
+	expected := "PRIORITY=3\nSYSLOG_IDENTIFIER=gologger\nMESSAGE=Disk is full\nFREE_BYTES=0\nMOUNT=/var\n"
+	t.Logf("Got: %q", out)
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, out)
+	}
+
+	// Multi-line messages use the binary safe framing.
+	l.Print(LevelWarn, "first\nsecond")
+	out = read()
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, uint64(len("first\nsecond")))
+	expected = "PRIORITY=4\nSYSLOG_IDENTIFIER=gologger\nMESSAGE\n" + string(size) + "first\nsecond\n"
+	t.Logf("Got: %q", out)
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, out)
+	}
+}
+
+func TestJournalKey(t *testing.T) {
+	for key, expected := range map[string]string{
+		"user":      "USER",
+		"http.path": "HTTP_PATH",
+		"_private":  "PRIVATE",
+		"1st":       "ST",
+		"*":         "FIELD",
+	} {
+		if got := journalKey(key); got != expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, got)
+		}
+	}
+}
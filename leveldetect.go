@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// These leading tokens are recognized by LevelDetectingWriter, case-insensitively.
+// FATAL is mapped to LevelError, so that a legacy library cannot make the logger exit.
+var detectedLevels = map[string]Level{
+	"FATAL":   LevelError,
+	"ERROR":   LevelError,
+	"ERR":     LevelError,
+	"WARNING": LevelWarn,
+	"WARN":    LevelWarn,
+	"INFO":    LevelInfo,
+	"DEBUG":   LevelDebug,
+	"TRACE":   LevelTrace,
+}
+
+// LevelDetectingWriter is an io.Writer logging every written line through a logger, at a level detected
+// from the leading token of the line like "ERROR:", "WARN " or "[INFO]". The token is removed from the message.
+// Lines without a recognized token are logged at the default level. Partial lines are kept until completed
+// by a later Write or until Flush is called. It is useful to capture output of libraries using the standard log package.
+type LevelDetectingWriter struct {
+	logger       ILogger
+	defaultLevel Level
+	buf          []byte
+	sync.Mutex
+}
+
+// NewLevelDetectingWriter returns a LevelDetectingWriter logging lines through l.
+func NewLevelDetectingWriter(l ILogger, defaultLevel Level) *LevelDetectingWriter {
+	return &LevelDetectingWriter{logger: l, defaultLevel: defaultLevel}
+}
+
+// Write logs every complete line of p, a trailing partial line is buffered.
+func (w *LevelDetectingWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs the buffered partial line, if any.
+func (w *LevelDetectingWriter) Flush() error {
+	w.Lock()
+	defer w.Unlock()
+	if len(w.buf) > 0 {
+		w.log(string(w.buf))
+		w.buf = w.buf[:0]
+	}
+	return nil
+}
+
+func (w *LevelDetectingWriter) log(line string) {
+	level, msg := detectLevel(strings.TrimSuffix(line, "\r"), w.defaultLevel)
+	w.logger.Print(level, msg)
+}
+
+// detectLevel returns the level of the leading token of line and the rest of the line,
+// or the default level and the unchanged line if there is no recognized token.
+func detectLevel(line string, defaultLevel Level) (Level, string) {
+	s := line
+	bracket := strings.HasPrefix(s, "[")
+	if bracket {
+		s = s[1:]
+	}
+	end := strings.IndexAny(s, ":] \t")
+	if end <= 0 {
+		return defaultLevel, line
+	}
+	level, ok := detectedLevels[strings.ToUpper(s[:end])]
+	if !ok || bracket != (s[end] == ']') {
+		return defaultLevel, line
+	}
+	s = s[end+1:]
+	if bracket && strings.HasPrefix(s, ":") {
+		s = s[1:]
+	}
+	return level, strings.TrimLeft(s, " \t")
+}
@@ -0,0 +1,39 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLevelDetectingWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	w := NewLevelDetectingWriter(l, LevelInfo)
+	_, _ = w.Write([]byte("ERROR: disk full\nWARN low memory\n[debug] cache miss\nplain line\nwarning: spl"))
+	_, _ = w.Write([]byte("it line\nFATAL: not exiting\nNOTICE: unknown\ntrailing"))
+	_ = w.Flush()
+
+	expected := []string{
+		"E disk full",
+		"W low memory",
+		"D cache miss",
+		"I plain line",
+		"W split line",
+		"E not exiting",
+		"I NOTICE: unknown",
+		"I trailing",
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, lines)
+	}
+	for i, line := range lines {
+		got := line[:1] + " " + line[13:]
+		if got != expected[i] {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected[i], got)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package logger
+
+func (l *logger) Fatal(v ...any) {
+	l.Print(LevelFatal, v...)
+}
+
+func (l *logger) Fatalf(format string, v ...any) {
+	l.Printf(LevelFatal, format, v...)
+}
+
+func (l *logger) Fatalln(v ...any) {
+	l.Println(LevelFatal, v...)
+}
+
+func (l *logger) Error(v ...any) {
+	l.Print(LevelError, v...)
+}
+
+func (l *logger) Errorf(format string, v ...any) {
+	l.Printf(LevelError, format, v...)
+}
+
+func (l *logger) Errorln(v ...any) {
+	l.Println(LevelError, v...)
+}
+
+func (l *logger) Warn(v ...any) {
+	l.Print(LevelWarn, v...)
+}
+
+func (l *logger) Warnf(format string, v ...any) {
+	l.Printf(LevelWarn, format, v...)
+}
+
+func (l *logger) Warnln(v ...any) {
+	l.Println(LevelWarn, v...)
+}
+
+func (l *logger) Info(v ...any) {
+	l.Print(LevelInfo, v...)
+}
+
+func (l *logger) Infof(format string, v ...any) {
+	l.Printf(LevelInfo, format, v...)
+}
+
+func (l *logger) Infoln(v ...any) {
+	l.Println(LevelInfo, v...)
+}
+
+func (l *logger) Debug(v ...any) {
+	l.Print(LevelDebug, v...)
+}
+
+func (l *logger) Debugf(format string, v ...any) {
+	l.Printf(LevelDebug, format, v...)
+}
+
+func (l *logger) Debugln(v ...any) {
+	l.Println(LevelDebug, v...)
+}
+
+func (l *logger) Trace(v ...any) {
+	l.Print(LevelTrace, v...)
+}
+
+func (l *logger) Tracef(format string, v ...any) {
+	l.Printf(LevelTrace, format, v...)
+}
+
+func (l *logger) Traceln(v ...any) {
+	l.Println(LevelTrace, v...)
+}
+
+// Fatal writes a LevelFatal entry using the default instance like Print, then exits with status 1.
+func Fatal(v ...any) {
+	Print(LevelFatal, v...)
+}
+
+// Fatalf writes a LevelFatal entry using the default instance like Printf, then exits with status 1.
+// It is a drop-in replacement for log.Fatalf.
+func Fatalf(format string, v ...any) {
+	Printf(LevelFatal, format, v...)
+}
+
+// Fatalln writes a LevelFatal entry using the default instance like Println, then exits with status 1.
+func Fatalln(v ...any) {
+	Println(LevelFatal, v...)
+}
+
+// Error writes a LevelError entry using the default instance, like Print.
+func Error(v ...any) {
+	Print(LevelError, v...)
+}
+
+// Errorf writes a LevelError entry using the default instance, like Printf.
+func Errorf(format string, v ...any) {
+	Printf(LevelError, format, v...)
+}
+
+// Errorln writes a LevelError entry using the default instance, like Println.
+func Errorln(v ...any) {
+	Println(LevelError, v...)
+}
+
+// Warn writes a LevelWarn entry using the default instance, like Print.
+func Warn(v ...any) {
+	Print(LevelWarn, v...)
+}
+
+// Warnf writes a LevelWarn entry using the default instance, like Printf.
+func Warnf(format string, v ...any) {
+	Printf(LevelWarn, format, v...)
+}
+
+// Warnln writes a LevelWarn entry using the default instance, like Println.
+func Warnln(v ...any) {
+	Println(LevelWarn, v...)
+}
+
+// Info writes an LevelInfo entry using the default instance, like Print.
+func Info(v ...any) {
+	Print(LevelInfo, v...)
+}
+
+// Infof writes an LevelInfo entry using the default instance, like Printf.
+func Infof(format string, v ...any) {
+	Printf(LevelInfo, format, v...)
+}
+
+// Infoln writes an LevelInfo entry using the default instance, like Println.
+func Infoln(v ...any) {
+	Println(LevelInfo, v...)
+}
+
+// Debug writes a LevelDebug entry using the default instance, like Print.
+func Debug(v ...any) {
+	Print(LevelDebug, v...)
+}
+
+// Debugf writes a LevelDebug entry using the default instance, like Printf.
+func Debugf(format string, v ...any) {
+	Printf(LevelDebug, format, v...)
+}
+
+// Debugln writes a LevelDebug entry using the default instance, like Println.
+func Debugln(v ...any) {
+	Println(LevelDebug, v...)
+}
+
+// Trace writes a LevelTrace entry using the default instance, like Print.
+func Trace(v ...any) {
+	Print(LevelTrace, v...)
+}
+
+// Tracef writes a LevelTrace entry using the default instance, like Printf.
+func Tracef(format string, v ...any) {
+	Printf(LevelTrace, format, v...)
+}
+
+// Traceln writes a LevelTrace entry using the default instance, like Println.
+func Traceln(v ...any) {
+	Println(LevelTrace, v...)
+}
@@ -0,0 +1,131 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// catchExit replaces the exit function for the duration of the test, the returned slice records the exit codes.
+func catchExit(t *testing.T) *[]int {
+	codes := new([]int)
+	SetExitFunc(func(code int) { *codes = append(*codes, code) })
+	t.Cleanup(func() { SetExitFunc(nil) })
+	return codes
+}
+
+func TestFatal(t *testing.T) {
+	codes := catchExit(t)
+	buf := new(bytes.Buffer)
+	l := New(LevelError, "", buf, 0)
+	defer std.SetOutput(std.GetOutput())
+	std.SetOutput(buf)
+	prints := []struct {
+		print    func()
+		expected string
+	}{
+		{func() { l.Fatal("Cannot start: ", "port taken") }, "Cannot start: port taken\n"},
+		{func() { l.Fatalf("Cannot start: %d", 8080) }, "Cannot start: 8080\n"},
+		{func() { l.Fatalln("Cannot", "start") }, "Cannot start\n"},
+		{func() { Fatal("Default ", "instance") }, "Default instance\n"},
+		{func() { Fatalf("Default %s", "instance") }, "Default instance\n"},
+		{func() { Fatalln("Default", "instance") }, "Default instance\n"},
+	}
+	for i, p := range prints {
+		buf.Reset()
+		p.print()
+		out := buf.String()
+		t.Log("Got: ", out)
+		if len(out) < 13 || out[0] != 'F' || out[13:] != p.expected {
+			t.Errorf("Pattern mismatch,\n\texpected: F/hh:mm:ss : %s\n\tgot: %s", p.expected, out)
+		}
+		if len(*codes) != i+1 || (*codes)[i] != 1 {
+			t.Errorf("Pattern mismatch,\n\texpected: exit code 1\n\tgot: %v", *codes)
+		}
+	}
+}
+
+func TestLevelMethods(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	prints := []struct {
+		print   func(v ...any)
+		printf  func(format string, v ...any)
+		println func(v ...any)
+		prefix  string
+	}{
+		{l.Error, l.Errorf, l.Errorln, "E"},
+		{l.Warn, l.Warnf, l.Warnln, "W"},
+		{l.Info, l.Infof, l.Infoln, "I"},
+		{l.Debug, l.Debugf, l.Debugln, "D"},
+		{l.Trace, l.Tracef, l.Traceln, "T"},
+	}
+	for _, p := range prints {
+		buf.Reset()
+		p.print("Count: ", 1)
+		p.printf("Count: %d", 2)
+		p.println("Count:", 3)
+		expected := ""
+		for i := 1; i <= 3; i++ {
+			expected += fmt.Sprintf("%s/ Count: %d\n", p.prefix, i)
+		}
+		got := ""
+		for _, line := range strings.SplitAfter(buf.String(), "\n") {
+			if len(line) > 13 {
+				got += line[:2] + " " + line[13:]
+			}
+		}
+		if got != expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+		}
+	}
+
+	// The level is still checked.
+	buf.Reset()
+	l.SetLevel(LevelWarn)
+	l.Info("Hidden")
+	l.Debugf("Hidden %d", 1)
+	l.Traceln("Hidden")
+	if buf.Len() != 0 {
+		t.Errorf("Entry above current level was written: %s", buf.String())
+	}
+}
+
+func TestDefaultLevelFunctions(t *testing.T) {
+	buf := new(bytes.Buffer)
+	defer std.SetOutput(std.GetOutput())
+	defer std.SetLevel(std.GetLevel())
+	std.SetOutput(buf)
+	std.SetLevel(LevelTrace)
+	prints := []struct {
+		print   func(v ...any)
+		printf  func(format string, v ...any)
+		println func(v ...any)
+		prefix  byte
+	}{
+		{Error, Errorf, Errorln, 'E'},
+		{Warn, Warnf, Warnln, 'W'},
+		{Info, Infof, Infoln, 'I'},
+		{Debug, Debugf, Debugln, 'D'},
+		{Trace, Tracef, Traceln, 'T'},
+	}
+	for _, p := range prints {
+		buf.Reset()
+		p.print("x")
+		p.printf("%s", "x")
+		p.println("x")
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		t.Log("Got: ", lines)
+		if len(lines) != 3 {
+			t.Fatalf("Pattern mismatch,\n\texpected: 3 entries\n\tgot: %d entries", len(lines))
+		}
+		for _, line := range lines {
+			if line[0] != p.prefix || line[13:] != "x" {
+				t.Errorf("Pattern mismatch,\n\texpected: %c/hh:mm:ss : x\n\tgot: %s", p.prefix, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !nolog
+
+package logger
+
+// noLog is true when built with the nolog tag: nothing is ever written and the Print functions and methods
+// are reduced to the exit of fatal entries, so that the compiler can eliminate the rest of their work.
+const noLog = false
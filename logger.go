@@ -5,15 +5,27 @@
 package logger
 
 import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 )
 
 // Level is an alias to int. It indicates the log level.
+// Lower values are more severe: LevelFatal < LevelError < ... < LevelTrace, so a logger set to a Level
+// writes the entries with that Level or a lower one. LevelQuiet is not a severity, but the threshold writing nothing.
+// Prefer IsAtLeast and MoreSevereThan over comparing levels directly, the inverted ordering is easy to get wrong.
 type Level int
 
 const (
@@ -28,11 +40,51 @@ const (
 	LevelTrace
 )
 
+// IsAtLeast tells whether a is as severe as b or more, e.g. IsAtLeast(LevelError, LevelWarn) is true.
+// It is false if either is LevelQuiet.
+func IsAtLeast(a, b Level) bool {
+	return a > LevelQuiet && b > LevelQuiet && a <= b
+}
+
+// MoreSevereThan tells whether the Level is strictly more severe than other, e.g. LevelFatal.MoreSevereThan(LevelError).
+// It is false if either is LevelQuiet.
+func (level Level) MoreSevereThan(other Level) bool {
+	return level > LevelQuiet && other > LevelQuiet && level < other
+}
+
 const (
 	// FlagColorMode indicates logs should be colorized based on their levels, e.g. red for LevelError.
+	// It is ignored when the NO_COLOR environment variable is set.
 	FlagColorMode = 1 << iota
+	// FlagNumericLevel indicates the integer value of the Level should be printed instead of its letter or label.
+	FlagNumericLevel
+	// FlagIndentMultiline indicates every additional line of a multi-line message should start with
+	// a continuation marker like "E| ", so that each physical line can be attributed to its level.
+	FlagIndentMultiline
+	// FlagAutoColor is like FlagColorMode, but only colorizes when the output is a terminal and the program runs
+	// interactively, with a terminal as standard output, or when FORCE_COLOR is set.
+	// Both are disabled when the NO_COLOR environment variable is set.
+	FlagAutoColor
+	// FlagSequence indicates a per-instance sequence number like "#42" should be printed after the time,
+	// so that downstream systems can detect dropped or reordered entries. It starts at 1, see ILogger.ResetSequence.
+	FlagSequence
+	// FlagNormalizeNewlines indicates the newlines inside entries, e.g. of multi-line messages, should be
+	// converted to the style set by ILogger.SetNewline too, instead of being written as they are.
+	FlagNormalizeNewlines
+	// FlagDeltaTime indicates the time elapsed since the previous entry of the instance, like "+5ms",
+	// should be printed after the time. The first entry gets "+0s".
+	FlagDeltaTime
+	// FlagTrimNewlines indicates trailing newlines of messages should be trimmed, so that a message ending
+	// with "\n\n" does not leave a blank line. Each entry then ends with a single terminator.
+	FlagTrimNewlines
+	// FlagPackage indicates the import path of the package which logged the entry, like package=github.com/acme/app/db,
+	// should be added as a field. It is coarser but cheaper to read than a file path.
+	FlagPackage
 )
 
+// ErrWriteTimeout is passed to the error handler when writing a log entry did not finish within the write timeout.
+var ErrWriteTimeout = errors.New("logger: write timed out")
+
 // These prefix characters are to be prepended to every log entries.
 var levelPrefixes = map[Level]string{
 	LevelFatal: "F",
@@ -43,6 +95,16 @@ var levelPrefixes = map[Level]string{
 	LevelTrace: "T",
 }
 
+// These names are used where a level has to be spelled out, e.g. in JSON payloads.
+var levelNames = map[Level]string{
+	LevelFatal: "fatal",
+	LevelError: "error",
+	LevelWarn:  "warn",
+	LevelInfo:  "info",
+	LevelDebug: "debug",
+	LevelTrace: "trace",
+}
+
 // These colors will be used to colorize logs when FlagColorMode is set.
 var levelColors = map[Level][]byte{
 	LevelFatal: []byte("\033[31;1m"),
@@ -53,8 +115,48 @@ var levelColors = map[Level][]byte{
 	LevelTrace: []byte("\033[36m"),
 }
 
+// NewlineStyle is the line terminator of entries, see ILogger.SetNewline.
+type NewlineStyle int
+
+const (
+	// NewlineLF terminates entries with "\n", as usual on Unix like systems.
+	NewlineLF NewlineStyle = iota
+	// NewlineCRLF terminates entries with "\r\n", as expected by some Windows tools.
+	NewlineCRLF
+)
+
+// Entry holds the parts of a log entry. It is passed to outputs implementing EntryWriter.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  []Field
+	// Sequence is the sequence number of the entry if FlagSequence is set, zero otherwise.
+	Sequence uint64
+}
+
+// EntryWriter can be implemented by outputs which need the parts of an entry instead of the formatted line.
+// If the output set by ILogger.SetOutput implements it, WriteEntry is called instead of Write.
+// The Entry and its Fields must not be retained after WriteEntry returns.
+type EntryWriter interface {
+	WriteEntry(e *Entry) error
+}
+
+// Hook is notified of every log entry written by a logger it is added to, see ILogger.AddHook.
+type Hook interface {
+	// Fire is called after the entry was written to the output. It is called synchronously, so it should return quickly.
+	Fire(e *Entry)
+}
+
 // ILogger is an interface for simple and easy logging system.
+//
+// The basic implementation returned by New is safe for concurrent use. SetLevel, SetFatalExits, SetLibraryMode and
+// SetRepanic are lock free, so the level check of disabled entries never waits. All other setters and getters take the
+// instance lock, which is also held while an entry is formatted and written, so a configuration change
+// applies from the next entry on and never to half of one. Hooks and the error handler are called after the lock is released.
 type ILogger interface {
+	slogOutput
+
 	// SetLevel sets the maximum Level to current instance.
 	// Entries greater than this Level should not be printed to output.
 	SetLevel(level Level)
@@ -63,23 +165,161 @@ type ILogger interface {
 
 	// SetFlags sets flags to the logger instance.
 	SetFlags(flags int)
+	// SetFlagsFromString sets flags from comma-separated names: color, numeric, indent, autocolor, sequence,
+	// normalize, delta, trim and package, e.g. "color,indent". Unknown names return an error and leave the flags unchanged.
+	SetFlagsFromString(s string) error
 	// GetFlags returns current flags of the logger instance.
 	GetFlags() int
+	// FlagsString returns the names of the current flags as accepted by SetFlagsFromString, e.g. "color,indent".
+	FlagsString() string
+
+	// ResetSequence restarts the sequence numbers printed by FlagSequence, the next entry is numbered 1.
+	ResetSequence()
+
+	// SetRememberLast sets whether the last formatted entry is kept, to be returned by LastEntry.
+	// It is cheap, the same buffer is reused for every entry. Disabling it frees the buffer.
+	SetRememberLast(remember bool)
+	// LastEntry returns a copy of the last entry written while SetRememberLast(true) was set, or nil.
+	// It is useful for interactive debugging and tests.
+	LastEntry() []byte
+
+	// SetLevelStyle adds text styles like Bold or Underline to the color of level, e.g. SetLevelStyle(LevelError, Bold).
+	// Calling it without styles restores the plain color.
+	SetLevelStyle(level Level, styles ...SGR)
+	// SetTheme replaces the level colors by the ones of theme, e.g. ThemeSolarized. ThemeBasic restores the default
+	// colors. Styles set by SetLevelStyle are kept. An unknown theme returns an error and changes nothing.
+	SetTheme(theme Theme) error
+	// SetLevelColor sets the escape sequence colorizing the entries of level, e.g. ColorRGB(255, 128, 0).
+	// A nil color restores the default one. Colors which are not SGR escape sequences, like "\033[31;1m", are ignored.
+	SetLevelColor(level Level, color []byte)
+	// SetColorScheme replaces all the level colors at once, the levels missing from scheme get their default color.
+	// A scheme with an unknown level or a color which is not an SGR escape sequence returns an error and changes nothing.
+	SetColorScheme(scheme map[Level][]byte) error
+	// AddColorRule adds a rule choosing the color of entries from their fields, e.g. red for status>=500.
+	// Rules are evaluated in the order they were added, the color of the first matching one wins over the level color.
+	// They only apply while colors are enabled, see FlagColorMode.
+	AddColorRule(rule ColorRule)
+
+	// SetEscalation logs an extra entry at the Level to once an entry at the Level from was repeated count times,
+	// with the same message, within window. It happens at most once per message and window. The extra entry
+	// has the same fields and tells about the escalation. It is disabled if to is not more severe than from.
+	SetEscalation(from, to Level, count int, window time.Duration)
+
+	// SetNewline sets the terminator of entries, NewlineLF by default. See also FlagNormalizeNewlines.
+	SetNewline(style NewlineStyle)
 
 	// SetPrefix sets a prefix to be used with every log entries.
 	SetPrefix(prefix string)
+	// SetPrefixTemplate is like SetPrefix, but expands placeholders: {pid} and {host} are replaced once
+	// by the process id and the host name, {time} is replaced for every entry by its date and time in RFC 3339.
+	SetPrefixTemplate(tmpl string)
 	// GetPrefix returns the prefix currently set.
 	GetPrefix() string
 
+	// SetLevelLabel sets a label to be used in place of the level letter and its separator for the given Level.
+	// For example "[ERROR] " makes error entries start with "[ERROR] " instead of "E/".
+	// Passing an empty label restores the default letter.
+	SetLevelLabel(level Level, label string)
+
+	// SetBodySeparator sets a separator to be inserted between the header and the message, e.g. "\t" for column parsing.
+	// It is empty by default, since the header already ends with a space.
+	SetBodySeparator(sep string)
+
+	// SetMaxLineLength sets the maximum length in bytes of written lines, header included.
+	// Instead of being truncated, longer lines are split into multiple lines of at most n bytes,
+	// each split line ending with a '\\' telling it continues on the next line. Values under 2 disable splitting.
+	SetMaxLineLength(n int)
+
+	// AddQuietWindow silences the entries of levels, or of all levels if none given, whose time is from start
+	// until end, e.g. during a maintenance. The time is given by the clock, see SetTimeFunc.
+	// Silenced LevelFatal entries still exit. Windows can not be removed, they are harmless once over.
+	AddQuietWindow(start, end time.Time, levels ...Level)
+
+	// SetDropSummary writes a LevelWarn entry like "dropped: error=12 warn=3" every interval if entries were dropped
+	// since the previous one: silenced by a quiet window, logged recursively or whose write timed out.
+	// A zero or negative interval stops the background goroutine, the default.
+	SetDropSummary(interval time.Duration)
+
+	// SetFatalExits controls whether LevelFatal entries call os.Exit, it is true by default.
+	// When set to false, LevelFatal entries are still written but the program keeps running.
+	// It is useful in tests or during graceful shutdown.
+	SetFatalExits(exit bool)
+	// SetLibraryMode makes LevelFatal entries be written as LevelError entries which never exit, whatever
+	// SetFatalExits says. Libraries embedding a logger should enable it, so that they never terminate their host.
+	SetLibraryMode(library bool)
+
 	// SetOutput sets an io.Writer as target where logs should be printed.
-	// For example os.Stderr can be used to log to console.
+	// For example os.Stderr can be used to log to console. A nil writer discards all entries.
+	// The swap waits for the entry being written, so an entry is never split across the old and new writers.
 	SetOutput(out io.Writer)
+	// SetLevelOutput routes the entries of level to out instead of the output set by SetOutput.
+	// A nil writer removes the route. The write timeout and retries only apply to the main output.
+	SetLevelOutput(level Level, out io.Writer)
+	// SetLeveledFileOutputs routes every level to its own file in dir, creating it if needed: error.log
+	// (with fatal entries too), warn.log, info.log, debug.log and trace.log. Each file is a RotatingFileWriter
+	// rotated independently at maxSize bytes. The files are closed by Close, or by the next call.
+	SetLeveledFileOutputs(dir string, maxSize int) error
 	// GetOutput returns an io.Writer where logs are to be written currently.
 	GetOutput() io.Writer
 
+	// AddHook adds a Hook to be fired with every written entry. Hooks are fired outside the lock in the order they were added.
+	AddHook(hook Hook)
+
+	// OnErrorStateChange calls fn with true when an error (or fatal) entry is written while no other was within window,
+	// and with false once no error was written for window. It notifies when errors start and stop instead of
+	// for every error, to avoid alert fatigue. fn is called like hooks, see AddHook. A zero window disables it.
+	OnErrorStateChange(window time.Duration, fn func(inError bool))
+
+	// SetTimeFunc sets the function returning the time of every entry, passing nil restores time.Now.
+	// It is useful to get deterministic timestamps in tests.
+	SetTimeFunc(now func() time.Time)
+	// SetCoarseTime makes the logger use a low resolution clock, updated every resolution by a background
+	// goroutine, instead of calling time.Now for every entry. It trades precision for speed under high logging rates.
+	// A zero or negative resolution stops the background goroutine and restores time.Now.
+	SetCoarseTime(resolution time.Duration)
+
+	// Flush flushes the output if it is buffered (has a Flush method, like bufio.Writer) or syncs it
+	// to disk if it has a Sync method, like os.File.
+	Flush() error
+	// Drain prepares the logger for shutdown: it waits for a write which timed out, writes the pending drop summary,
+	// flushes the output and the level outputs, closes the hooks having a Close method, like Webhook, which waits
+	// for their queued work, and stops the background goroutines of SetCoarseTime and SetDropSummary.
+	// It returns the first error, or the error of ctx if it is done first, the remaining work then goes on
+	// in the background. Entries logged afterwards are still written, but closed hooks drop them.
+	Drain(ctx context.Context) error
+	// Close drains the logger like Drain, without a deadline, then closes the outputs the logger opened,
+	// like the file of NewFileLogger, the files of SetLeveledFileOutputs or the pipe of NewPipeLogger,
+	// waiting for its process to exit.
+	// Other outputs are left open.
+	Close() error
+	// Capture calls fn with the entries of the logger, including the ones of level outputs, kept in memory instead
+	// of being written, and returns them. The outputs are restored once fn returns, or panics, discarding outputs
+	// set by fn. It is meant for tests and focused debugging.
+	Capture(fn func()) []Entry
+	// FlushOnSignals calls Close when one of the signals arrives, os.Interrupt if none given, so that the output
+	// is flushed, the hooks and the output opened by the logger are closed, for at most 5 seconds. Then the signal
+	// is delivered again with its default behavior, which usually terminates the program.
+	// Call the returned function to stop handling the signals.
+	FlushOnSignals(signals ...os.Signal) (stop func())
+
+	// SetErrorHandler sets a function to be called when writing a log entry fails.
+	// Errors are silently dropped if no handler is set. The handler must not log to the same instance.
+	SetErrorHandler(handler func(err error))
+
+	// SetWriteTimeout limits how long writing a log entry may block, a zero or negative duration disables it.
+	// On timeout the entry is dropped and ErrWriteTimeout is passed to the error handler. Writers having a
+	// SetWriteDeadline method (like net.Conn) get a deadline, cleared after every write. Other writers are run
+	// in a goroutine and further entries are dropped until the blocked write returns.
+	SetWriteTimeout(d time.Duration)
+	// SetWriteRetry retries failed writes to the output up to attempts times before the error handler is called,
+	// sleeping backoff before the first retry and doubling it before each next one. Permanent errors, like a closed
+	// file or a broken pipe, and write timeouts are not retried. Other entries wait during the backoff.
+	// Zero attempts disables retries, the default.
+	SetWriteRetry(attempts int, backoff time.Duration)
+
 	// Print writes a log entry to the output. Behaves like fmt.Print standard function.
 	// It should return immediately (writing nothing) if current log level is smaller than the passed Level.
-	// But if the passed Level is LevelFatal, then os.Exit should be called before return.
+	// But if the passed Level is LevelFatal, then the logger should be drained, see Drain, and os.Exit called before return.
 	Print(level Level, v ...any)
 
 	// Println writes a log entry to the output. Behaves like fmt.Println standard function.
@@ -92,16 +332,149 @@ type ILogger interface {
 	// But if the passed Level is LevelFatal, then os.Exit should be called before return.
 	Printf(level Level, format string, v ...any)
 
+	// Fatal, Fatalf and Fatalln are like Print, Printf and Println with LevelFatal, matching the standard log package.
+	// The program exits with status 1 after the entry is written, unless disabled by SetFatalExits(false).
+	Fatal(v ...any)
+	Fatalf(format string, v ...any)
+	Fatalln(v ...any)
+	// Error, Warn, Info, Debug and Trace and their f and ln variants are like Print, Printf and Println
+	// with the Level of their name.
+	Error(v ...any)
+	Errorf(format string, v ...any)
+	Errorln(v ...any)
+	Warn(v ...any)
+	Warnf(format string, v ...any)
+	Warnln(v ...any)
+	Info(v ...any)
+	Infof(format string, v ...any)
+	Infoln(v ...any)
+	Debug(v ...any)
+	Debugf(format string, v ...any)
+	Debugln(v ...any)
+	Trace(v ...any)
+	Tracef(format string, v ...any)
+	Traceln(v ...any)
+
+	// Writer returns an io.Writer logging every line written to it as an entry of level, e.g. to pass the logger
+	// to a library expecting an io.Writer. Lines split across several writes make a single entry, the last
+	// partial line is kept until it is complete or the writer is flushed.
+	Writer(level Level) *LevelWriter
+
+	// PrintSync is like Print, but flushes the output the entry was written to before returning, see Flush,
+	// so that the entry is durably written. Errors are returned instead of being passed to the error handler.
+	PrintSync(level Level, v ...any) error
+
+	// PrintTo is like Print, but writes the entry to w instead of the configured output.
+	// It is useful for one-off entries like audit logs. The write timeout does not apply to w.
+	PrintTo(w io.Writer, level Level, v ...any)
+
+	// PrintAt is like Print, but uses t as the time of the entry instead of the clock.
+	// It is useful to replay historical events with their original time.
+	PrintAt(t time.Time, level Level, v ...any)
+
+	// Begin starts a transaction gathering entries during an operation. Nothing is written until Commit is called,
+	// so that the entries can be dropped by Discard, e.g. when the operation succeeded.
+	Begin() Tx
+
+	// PrintFields writes a log entry with the message followed by the fields rendered as key=value pairs.
+	// String values are quoted if needed. The Level is handled like in Print.
+	PrintFields(level Level, msg string, fields ...Field)
+	// PrintCoded writes a log entry with the message formatted like fmt.Printf, followed by a code=<code> field.
+	// It is useful to group errors of an error catalog. The Level is handled like in Print.
+	PrintCoded(level Level, code string, msg string, v ...any)
+	// PrintContext is like PrintFields, adding the fields returned by the context extractors for ctx.
+	PrintContext(ctx context.Context, level Level, msg string, fields ...Field)
+	// AddContextExtractor adds an extractor called by PrintContext, e.g. DeadlineExtractor.
+	AddContextExtractor(extractor ContextExtractor)
+	// SetFieldOrder sets the order in which fields are rendered, FieldOrderSorted by default.
+	SetFieldOrder(order FieldOrder)
+	// SetMaxFields caps the number of fields rendered per entry to n, zero or negative means no limit.
+	// Extra fields are dropped, in the order they were passed, and a fields_truncated=true field is appended.
+	SetMaxFields(n int)
+	// SetVersion adds a version=<v> field to every entry, e.g. the release the program was built from.
+	// It is meant to be called once at start up, an empty version removes the field.
+	SetVersion(v string)
+
+	// PrintAccess writes a log entry with the HTTP request rendered in the Common Log Format, e.g.
+	// 127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /a.gif HTTP/1.0" 200 2326
+	// The Combined Log Format is used if the referer or the user agent is set. The Level is handled like in Print.
+	PrintAccess(level Level, e AccessEntry)
+	// LogRequest writes an "HTTP request" entry with the method, path, status, duration and remote address
+	// of r as fields, plus the user agent if set. Other headers and the query string are never logged,
+	// since they may carry credentials like Authorization or Cookie. The Level is handled like in Print.
+	LogRequest(level Level, r *http.Request, status int, dur time.Duration)
+	// PrintBanner writes a "Logger started" entry with the level, the flags, the host name and the process id
+	// as fields, plus the version if set, see SetVersion. It is meant as the first entry of a log file.
+	// The Level is handled like in Print.
+	PrintBanner(level Level)
+	// Middleware returns an http.Handler calling next with a child logger in the context of the request, see
+	// NewContext. The child adds a generated request_id, the method, the path and the remote address of the
+	// request to its entries, so that handlers get them by logging to FromContext(r.Context()).
+	Middleware(next http.Handler) http.Handler
+	// SetIDGenerator sets the function generating the correlation ids of the logger, like the request_id
+	// of Middleware, e.g. to get UUIDs. Passing nil restores the default, random ids of 16 base62 digits.
+	SetIDGenerator(gen func() string)
+
+	// PrintHex writes a log entry with the label followed by a hex dump of data, like hex.Dump does.
+	// Every line of the dump is indented by two spaces. The Level is handled like in Print.
+	PrintHex(level Level, label string, data []byte)
+
+	// RecoverAndLog recovers a panic and logs its value along with the stack trace at the given Level.
+	// It must be deferred directly, e.g. defer l.RecoverAndLog(LevelError).
+	// The panic is swallowed unless SetRepanic(true) was called, in that case it continues after logging.
+	RecoverAndLog(level Level)
+	// SetRepanic sets whether RecoverAndLog should panic again after logging a recovered panic.
+	SetRepanic(repanic bool)
+	// SetStackDedupWindow makes RecoverAndLog log the full stack trace only for the first panic raised
+	// at a call site within window, later ones refer to the time it was logged. Zero disables it, the default.
+	SetStackDedupWindow(window time.Duration)
+
+	// WithLevel calls SetLevel and returns the same instance, so that configuration calls can be chained.
+	WithLevel(level Level) ILogger
+	// WithFlags calls SetFlags and returns the same instance, so that configuration calls can be chained.
+	WithFlags(flags int) ILogger
+	// WithPrefix calls SetPrefix and returns the same instance, so that configuration calls can be chained.
+	WithPrefix(prefix string) ILogger
+	// WithOutput calls SetOutput and returns the same instance, so that configuration calls can be chained.
+	WithOutput(out io.Writer) ILogger
+
+	// WithFields returns a child logger, a copy of the instance like Clone, adding the fields to every entry before
+	// the fields passed to it. Fields passed to it override the ones of the child having the same key, which is
+	// rendered once. They do not count toward SetMaxFields. The child shares the output and the clock of the instance,
+	// so the output should be safe for concurrent use, like os.File. It does not write drop summaries.
+	WithFields(fields ...Field) ILogger
+
+	// InheritFrom copies the formatting configuration of other into the current instance: level, flags, prefix,
+	// level labels, body separator, newline style, colors and styles, maximum line length, field order, maximum
+	// number of fields, version and the fields set by WithFields. The output is kept,
+	// call SetOutput(other.GetOutput()) to share it too. Only level, flags and prefix are copied
+	// from ILogger implementations other than the basic one.
+	InheritFrom(other ILogger)
+
+	// MarshalConfig returns the configuration of the instance as JSON, to be restored later by LoadConfig.
+	// Hooks, handlers and clocks are not included. The output is referenced by name: "stderr", "stdout",
+	// "discard" or the path of a file. It is omitted if it can not be named, e.g. a network connection.
+	MarshalConfig() ([]byte, error)
+	// LoadConfig applies a configuration returned by MarshalConfig. Files are opened for appending,
+	// unless the current output is the same file, and are closed by Close or when a later configuration replaces
+	// the output. Omitted fatal_exits keeps the current setting. Nothing is changed if the configuration is invalid,
+	// e.g. has unknown flags, newline style or field order.
+	LoadConfig(data []byte) error
+
 	// Clone returns an identical copy of the current log instance.
 	// It is useful when you need to create multiple loggers with similar configuration.
 	Clone() ILogger
 }
 
 // Cheap integer to fixed-width decimal ASCII. Give a negative width to avoid zero-padding.
+// The integer must not be negative, widths above 20 are padded to 20 digits only.
 func iToA(buf *[]byte, i int, wid int) {
 	// Assemble decimal in reverse order.
 	var b [20]byte
 	bp := len(b) - 1
+	if wid > len(b) {
+		wid = len(b)
+	}
 	for i >= 10 || wid > 1 {
 		wid--
 		q := i / 10
@@ -114,13 +487,96 @@ func iToA(buf *[]byte, i int, wid int) {
 	*buf = append(*buf, b[bp:]...)
 }
 
+// buffer is a byte slice usable as an io.Writer, so that fmt functions can format directly into it.
+type buffer []byte
+
+func (b *buffer) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
 // logger is a simple implementation of ILogger to be used out of the box.
 type logger struct {
+	// writer is the id of the goroutine writing to an output which may log, see guardWrite. It is accessed
+	// atomically, so it comes first to be 64-bit aligned on 32-bit platforms.
+	writer uint64
 	level  int32
 	prefix string
-	flags  int
-	out    io.Writer
-	buf    []byte
+	// prefixTime tells whether the prefix is a template containing the {time} placeholder.
+	prefixTime bool
+	flags      int
+	out        io.Writer
+	// closer is the output opened by the logger itself, levelClosers are the level outputs it opened, see Close.
+	closer       io.Closer
+	levelClosers []io.Closer
+	// levelOut holds the outputs set per level, overriding out.
+	levelOut map[Level]io.Writer
+	// terminal tells whether out is a terminal, it is checked once when the output is set.
+	terminal bool
+	buf      buffer
+	tmp      []byte
+	labels   map[Level]string
+	// headers caches the static header of every level, see resetHeaders.
+	headers [LevelTrace + 1]staticHeader
+	// colors holds the level colors of the theme, overriding levelColors, styles holds the escape sequences
+	// set by SetLevelStyle, overriding both.
+	colors map[Level][]byte
+	styles map[Level]levelStyle
+	// colorCache holds the resulting color of every level, see resetColors.
+	colorCache colorCache
+	// colorRules choose the color of entries from their fields, before the level color.
+	colorRules []ColorRule
+	bodySep    string
+	newline    NewlineStyle
+	// last is the last entry formatted, kept only when rememberLast is set.
+	rememberLast bool
+	last         []byte
+	// maxLineLen is the maximum length of a line, longer lines are split.
+	maxLineLen int
+	// fieldOrder and maxFields tell how fields are rendered, fieldBuf is reused to prepare them.
+	fieldOrder FieldOrder
+	fieldBuf   []Field
+	maxFields  int
+	// fields are rendered with every entry, before the ones passed. version is rendered as a field if set.
+	fields  []Field
+	version string
+	// idGen generates correlation ids, see newID.
+	idGen func() string
+	// fatalExits is 1 if LevelFatal entries should call os.Exit, 0 otherwise.
+	fatalExits int32
+	// libraryMode is 1 if LevelFatal entries should be written as LevelError entries without exiting.
+	libraryMode int32
+	repanic     int32
+	// stackWindow is the stack trace dedup window, stackSeen the time each panic site was first logged.
+	stackWindow time.Duration
+	stackSeen   map[string]time.Time
+	onError     func(error)
+	hooks       []Hook
+	// quiet holds the windows set by AddQuietWindow, drops counts the dropped entries if set.
+	quiet      []quietWindow
+	drops      *dropSummary
+	extractors []ContextExtractor
+	// writeTimeout bounds every write to the output, pending is closed when a timed out write returns.
+	writeTimeout time.Duration
+	pending      chan struct{}
+	// retryAttempts and retryBackoff tell how failed writes are retried.
+	retryAttempts int
+	retryBackoff  time.Duration
+	// now returns the time of entries, coarse is set when it is a coarse clock.
+	now    func() time.Time
+	coarse *coarseClock
+	// lastTime is the time of the last entry, used by FlagDeltaTime.
+	lastTime time.Time
+	// seq is the sequence number of the last entry, it is only incremented while FlagSequence is set.
+	seq uint64
+	// escalation counts repeated entries, escalated is the message of the entry to be escalated once unlocked.
+	escalation *escalation
+	escalated  string
+	// callbacks counts the hooks and error handlers running per goroutine id, to detect recursive logging.
+	// writeRecursed is set when that output logged back, so that the warning is written once the write returns.
+	callbacks       map[uint64]int
+	writeRecursed   bool
+	recursionWarned bool
 	sync.Mutex
 }
 
@@ -142,6 +598,8 @@ func (l *logger) SetFlags(flags int) {
 	l.Lock()
 	defer l.Unlock()
 	l.flags = flags
+	l.resetHeaders()
+	l.resetColors()
 }
 
 func (l *logger) GetFlags() int {
@@ -154,6 +612,8 @@ func (l *logger) SetPrefix(prefix string) {
 	l.Lock()
 	defer l.Unlock()
 	l.prefix = prefix
+	l.prefixTime = false
+	l.resetHeaders()
 }
 
 func (l *logger) GetPrefix() string {
@@ -162,10 +622,123 @@ func (l *logger) GetPrefix() string {
 	return l.prefix
 }
 
+func (l *logger) SetLevelLabel(level Level, label string) {
+	l.Lock()
+	defer l.Unlock()
+	l.resetHeaders()
+	if label == "" {
+		delete(l.labels, level)
+		return
+	}
+	if l.labels == nil {
+		l.labels = make(map[Level]string)
+	}
+	l.labels[level] = label
+}
+
+func (l *logger) SetBodySeparator(sep string) {
+	l.Lock()
+	defer l.Unlock()
+	l.bodySep = sep
+	l.resetHeaders()
+}
+
+func (l *logger) SetMaxLineLength(n int) {
+	l.Lock()
+	defer l.Unlock()
+	l.maxLineLen = n
+}
+
+func (l *logger) SetFatalExits(exit bool) {
+	var v int32
+	if exit {
+		v = 1
+	}
+	atomic.StoreInt32(&l.fatalExits, v)
+}
+
+func (l *logger) SetLibraryMode(library bool) {
+	var v int32
+	if library {
+		v = 1
+	}
+	atomic.StoreInt32(&l.libraryMode, v)
+}
+
 func (l *logger) SetOutput(out io.Writer) {
 	l.Lock()
 	defer l.Unlock()
 	l.out = out
+	l.terminal = isTerminal(out)
+	l.resetColors()
+}
+
+func (l *logger) SetLevelOutput(level Level, out io.Writer) {
+	l.Lock()
+	defer l.Unlock()
+	if out == nil {
+		delete(l.levelOut, level)
+		return
+	}
+	if l.levelOut == nil {
+		l.levelOut = make(map[Level]io.Writer)
+	}
+	l.levelOut[level] = out
+}
+
+func (l *logger) AddHook(hook Hook) {
+	l.Lock()
+	defer l.Unlock()
+	// Always copy, a hooks slice returned by format may still be in use.
+	l.hooks = append(l.hooks[:len(l.hooks):len(l.hooks)], hook)
+}
+
+func (l *logger) SetErrorHandler(handler func(err error)) {
+	l.Lock()
+	defer l.Unlock()
+	l.onError = handler
+}
+
+func (l *logger) SetWriteTimeout(d time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	l.writeTimeout = d
+}
+
+func (l *logger) SetRememberLast(remember bool) {
+	l.Lock()
+	defer l.Unlock()
+	l.rememberLast = remember
+	if !remember {
+		l.last = nil
+	}
+}
+
+func (l *logger) LastEntry() []byte {
+	l.Lock()
+	defer l.Unlock()
+	if l.last == nil {
+		return nil
+	}
+	return append([]byte(nil), l.last...)
+}
+
+func (l *logger) SetNewline(style NewlineStyle) {
+	l.Lock()
+	defer l.Unlock()
+	l.newline = style
+}
+
+func (l *logger) ResetSequence() {
+	l.Lock()
+	defer l.Unlock()
+	l.seq = 0
+}
+
+func (l *logger) SetWriteRetry(attempts int, backoff time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	l.retryAttempts, l.retryBackoff = attempts, backoff
 }
 
 func (l *logger) GetOutput() io.Writer {
@@ -174,10 +747,57 @@ func (l *logger) GetOutput() io.Writer {
 	return l.out
 }
 
+// staticHeader holds the parts of the header of a level which are the same for every entry: lead is written
+// before the time, tail after the sequence number. tail holds the prefix unless it is a template with {time}.
+type staticHeader struct {
+	lead, tail []byte
+	ready      bool
+}
+
+// staticHeader returns the static header of level, preparing it on first use.
+// It must be called with the lock held.
+func (l *logger) staticHeader(level Level) *staticHeader {
+	if level < LevelQuiet || level > LevelTrace {
+		h := l.newStaticHeader(level)
+		return &h
+	}
+	h := &l.headers[level]
+	if !h.ready {
+		*h = l.newStaticHeader(level)
+	}
+	return h
+}
+
+// newStaticHeader prepares the static header of level. It must be called with the lock held.
+func (l *logger) newStaticHeader(level Level) staticHeader {
+	var lead []byte
+	if l.flags&FlagNumericLevel != 0 {
+		iToA(&lead, int(level), -1)
+		lead = append(lead, '/')
+	} else if label, ok := l.labels[level]; ok {
+		lead = append(lead, label...)
+	} else {
+		lead = append(lead, levelPrefixes[level]...)
+		lead = append(lead, '/')
+	}
+	var tail []byte
+	if !l.prefixTime {
+		tail = append(tail, l.prefix...)
+	}
+	tail = append(tail, ": "...)
+	tail = append(tail, l.bodySep...)
+	return staticHeader{lead: lead, tail: tail, ready: true}
+}
+
+// resetHeaders drops the cached static headers, it must be called by setters changing the flags, prefix,
+// level labels or body separator. It must be called with the lock held.
+func (l *logger) resetHeaders() {
+	l.headers = [LevelTrace + 1]staticHeader{}
+}
+
 func (l *logger) buildHeader(level Level, buf *[]byte, t time.Time) {
-	pref, _ := levelPrefixes[level]
-	*buf = append(*buf, pref...)
-	*buf = append(*buf, '/')
+	h := l.staticHeader(level)
+	*buf = append(*buf, h.lead...)
 	hour, min, sec := t.Clock()
 	iToA(buf, hour, 2)
 	*buf = append(*buf, ':')
@@ -185,89 +805,604 @@ func (l *logger) buildHeader(level Level, buf *[]byte, t time.Time) {
 	*buf = append(*buf, ':')
 	iToA(buf, sec, 2)
 	*buf = append(*buf, ' ')
-	*buf = append(*buf, l.prefix...)
-	*buf = append(*buf, ": "...)
+	if l.flags&FlagDeltaTime != 0 {
+		var d time.Duration
+		if !l.lastTime.IsZero() {
+			d = t.Sub(l.lastTime).Round(time.Microsecond)
+		}
+		if d >= 0 {
+			*buf = append(*buf, '+')
+		}
+		*buf = append(*buf, d.String()...)
+		*buf = append(*buf, ' ')
+	}
+	l.lastTime = t
+	if l.flags&FlagSequence != 0 {
+		*buf = append(*buf, '#')
+		*buf = strconv.AppendUint(*buf, l.seq, 10)
+		*buf = append(*buf, ' ')
+	}
+	if l.prefixTime {
+		l.appendPrefix(buf, t)
+	}
+	*buf = append(*buf, h.tail...)
+}
+
+// printOut writes a log entry to the output. The message body is formatted by writeBody
+// directly into the internal buffer, avoiding an intermediate string allocation.
+// Fields, if any, are rendered after the body.
+func (l *logger) printOut(level Level, fields []Field, writeBody func(b *buffer)) error {
+	return l.printTo(nil, time.Time{}, level, fields, writeBody)
+}
+
+// printTo is like printOut, but writes to out instead of the configured output, unless out is nil.
+// The entry time is t, or the current time if t is zero. The write timeout only applies to the configured output.
+func (l *logger) printTo(out io.Writer, t time.Time, level Level, fields []Field, writeBody func(b *buffer)) error {
+	if level == LevelFatal && atomic.LoadInt32(&l.libraryMode) != 0 {
+		level = LevelError
+	}
+	p := l.printLocked(out, t, level, fields, writeBody)
+	if len(p.hooks) > 0 {
+		l.runCallback(func() {
+			for _, h := range p.hooks {
+				h.Fire(p.entry)
+			}
+		})
+	}
+	e := p.err
+	if p.escalated != "" {
+		msg := p.esc.escalatedMessage(p.escalated)
+		if e2 := l.printTo(out, time.Time{}, p.esc.to, p.fields, func(b *buffer) { *b = append(*b, msg...) }); e == nil {
+			e = e2
+		}
+		l.exitIfFatal(p.esc.to)
+	}
+	return e
 }
 
-func (l *logger) printOut(level Level, s string) error {
-	now := time.Now()
+// printed is what printLocked leaves to be done once the lock is released.
+type printed struct {
+	fields    []Field
+	hooks     []Hook
+	entry     *Entry
+	escalated string
+	esc       *escalation
+	err       error
+}
+
+// printLocked formats and writes the entry with the lock held. The lock is released by a deferred call,
+// so that a panicking writer or field value does not leave the logger locked once the panic is recovered.
+func (l *logger) printLocked(out io.Writer, t time.Time, level Level, fields []Field, writeBody func(b *buffer)) (p printed) {
+	if l.recursiveWrite() {
+		l.countDrop(level)
+		return p
+	}
 	l.Lock()
 	defer l.Unlock()
+	if l.recursive() {
+		l.countDrop(level)
+		return p
+	}
+	if t.IsZero() {
+		t = l.now()
+	}
+	if len(l.quiet) > 0 && l.isQuiet(t, level) {
+		l.countDrop(level)
+		return p
+	}
+	if l.flags&FlagPackage != 0 {
+		// Never append to the caller's slice.
+		fields = append(fields[:len(fields):len(fields)], String("package", callerPackage()))
+	}
+	p.fields = fields
+	p.hooks, p.entry, p.err = l.format(out, t, level, fields, writeBody)
+	l.countFailedWrite(level, p.err)
+	p.escalated, p.esc = l.escalated, l.escalation
+	l.escalated = ""
+	return p
+}
+
+// format builds the entry and writes it to out, or to the configured output if out is nil.
+// If hooks are set, it also returns them with a copy of the entry to be fired once the lock is released.
+// It must be called with the lock held.
+func (l *logger) format(out io.Writer, now time.Time, level Level, fields []Field, writeBody func(b *buffer)) ([]Hook, *Entry, error) {
+	if out == nil {
+		out = l.out
+		if routed, ok := l.levelOut[level]; ok {
+			out = routed
+		}
+	}
+	if out == nil || noLog {
+		// Nothing to write to, logging is a no-op like with io.Discard.
+		return nil, nil, nil
+	}
 	l.buf = l.buf[:0]
-	color, hasColor := levelColors[level]
-	if hasColor = hasColor && l.flags&FlagColorMode != 0; hasColor {
-		l.buf = append(l.buf, color...)
+	color := l.entryColor(level, fields)
+	hasColor := color != nil
+	l.buf = append(l.buf, color...)
+	var seq uint64
+	if l.flags&FlagSequence != 0 {
+		l.seq++
+		seq = l.seq
 	}
-	l.buildHeader(level, &l.buf, now)
-	l.buf = append(l.buf, s...)
-	if len(s) == 0 || s[len(s)-1] != '\n' {
+	lineStart := len(l.buf)
+	l.buildHeader(level, (*[]byte)(&l.buf), now)
+	start := len(l.buf)
+	writeBody(&l.buf)
+	end := len(l.buf)
+	if end > start && l.buf[end-1] == '\n' {
+		end--
+	}
+	if l.flags&FlagTrimNewlines != 0 {
+		for end > start && (l.buf[end-1] == '\n' || l.buf[end-1] == '\r') {
+			end--
+		}
+		l.buf = l.buf[:end]
+	}
+	if l.escalation != nil && level == l.escalation.from && l.countRepeat(now, l.buf[start:end]) {
+		l.escalated = string(l.buf[start:end])
+	}
+	fields = l.prepareFields(fields)
+	var entry *Entry
+	if len(l.hooks) > 0 {
+		entry = &Entry{
+			Time:     now,
+			Level:    level,
+			Message:  string(l.buf[start:end]),
+			Fields:   append([]Field(nil), fields...),
+			Sequence: seq,
+		}
+	}
+	if !plainOutput(out) {
+		defer l.guardWrite()()
+	}
+	if ew, ok := out.(EntryWriter); ok {
+		e := entry
+		if e == nil {
+			e = &Entry{Time: now, Level: level, Message: string(l.buf[start:end]), Fields: fields, Sequence: seq}
+		}
+		if l.rememberLast {
+			l.last = append(append(l.last[:0], l.buf[:end]...), '\n')
+		}
+		return l.hooks, entry, ew.WriteEntry(e)
+	}
+	if l.flags&FlagIndentMultiline != 0 {
+		end = l.indentMultiline(level, start, end)
+	}
+	if len(fields) > 0 {
+		l.buf = appendFields(l.buf[:end], fields)
+	}
+	if len(l.buf) == start || l.buf[len(l.buf)-1] != '\n' {
 		l.buf = append(l.buf, '\n')
 	}
+	if l.maxLineLen > 1 {
+		l.splitLongLines(lineStart)
+	}
+	if l.flags&FlagNormalizeNewlines != 0 {
+		l.normalizeNewlines(lineStart)
+	} else if n := len(l.buf); l.newline == NewlineCRLF && (n < 2 || l.buf[n-2] != '\r') {
+		l.buf = append(l.buf[:n-1], "\r\n"...)
+	}
 	if hasColor {
 		l.buf = append(l.buf, "\033[0m"...)
 	}
-	_, e := l.out.Write(l.buf)
+	if l.rememberLast {
+		l.last = append(l.last[:0], l.buf...)
+	}
+	if out != l.out {
+		_, e := out.Write(l.buf)
+		return l.hooks, entry, e
+	}
+	return l.hooks, entry, l.write(l.buf)
+}
+
+// splitLongLines splits every line from start on which is longer than the maximum line length into
+// multiple lines. Each split line ends with a '\\' marker telling the line continues on the next one.
+// It must be called with the lock held.
+func (l *logger) splitLongLines(start int) {
+	n := l.maxLineLen
+	l.tmp = append(l.tmp[:0], l.buf[start:]...)
+	l.buf = l.buf[:start]
+	text := l.tmp
+	for len(text) > 0 {
+		i := bytes.IndexByte(text, '\n') + 1
+		line := text[:i]
+		text = text[i:]
+		for len(line)-1 > n {
+			cut := n - 1
+			// Do not cut a multi-byte character in two.
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				cut = n - 1
+			}
+			l.buf = append(l.buf, line[:cut]...)
+			l.buf = append(l.buf, "\\\n"...)
+			line = line[cut:]
+		}
+		l.buf = append(l.buf, line...)
+	}
+}
+
+// normalizeNewlines converts every newline from start on, "\n" or "\r\n", to the configured style.
+// It must be called with the lock held.
+func (l *logger) normalizeNewlines(start int) {
+	l.tmp = append(l.tmp[:0], l.buf[start:]...)
+	l.buf = l.buf[:start]
+	text := l.tmp
+	for i := bytes.IndexByte(text, '\n'); i >= 0; i = bytes.IndexByte(text, '\n') {
+		line := text[:i]
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+		l.buf = append(l.buf, line...)
+		if l.newline == NewlineCRLF {
+			l.buf = append(l.buf, '\r')
+		}
+		l.buf = append(l.buf, '\n')
+		text = text[i+1:]
+	}
+	l.buf = append(l.buf, text...)
+}
+
+// indentMultiline prefixes every line of the body but the first one with a continuation marker made of
+// the level letter (or number) and "| ". It returns the new end of the body and must be called with the lock held.
+func (l *logger) indentMultiline(level Level, start, end int) int {
+	if bytes.IndexByte(l.buf[start:end], '\n') < 0 {
+		return end
+	}
+	l.tmp = append(l.tmp[:0], l.buf[start:end]...)
+	l.buf = l.buf[:start]
+	body := l.tmp
+	for i := bytes.IndexByte(body, '\n'); i >= 0; i = bytes.IndexByte(body, '\n') {
+		l.buf = append(l.buf, body[:i+1]...)
+		if l.flags&FlagNumericLevel != 0 {
+			iToA((*[]byte)(&l.buf), int(level), -1)
+		} else {
+			l.buf = append(l.buf, levelPrefixes[level]...)
+		}
+		l.buf = append(l.buf, "| "...)
+		body = body[i+1:]
+	}
+	l.buf = append(l.buf, body...)
+	return len(l.buf)
+}
+
+// write writes p to the output, retrying failed writes as set by SetWriteRetry.
+// It must be called with the lock held.
+func (l *logger) write(p []byte) error {
+	n, e := l.writeOnce(p)
+	backoff := l.retryBackoff
+	for i := 0; e != nil && i < l.retryAttempts && retryable(e); i++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		// Only write the rest of p after a partial write, so nothing is duplicated.
+		p = p[n:]
+		n, e = l.writeOnce(p)
+	}
 	return e
 }
 
-func (l *logger) Print(level Level, v ...any) {
-	if atomic.LoadInt32(&l.level) < int32(level) {
-		if level == LevelFatal {
-			os.Exit(1)
+// retryable tells whether a failed write is worth retrying. Closed outputs and broken pipes are permanent,
+// timed out writes are still pending. Other errors are considered transient.
+func retryable(e error) bool {
+	if errors.Is(e, ErrWriteTimeout) || errors.Is(e, os.ErrClosed) || errors.Is(e, io.ErrClosedPipe) ||
+		errors.Is(e, os.ErrPermission) {
+		return false
+	}
+	for _, errno := range permanentErrnos {
+		if errors.Is(e, errno) {
+			return false
 		}
+	}
+	return true
+}
+
+// writeOnce writes p to the output, bounded by the write timeout if one is set.
+// It must be called with the lock held.
+func (l *logger) writeOnce(p []byte) (int, error) {
+	if l.writeTimeout <= 0 {
+		return l.out.Write(p)
+	}
+	if dw, ok := l.out.(interface{ SetWriteDeadline(t time.Time) error }); ok {
+		if e := dw.SetWriteDeadline(time.Now().Add(l.writeTimeout)); e == nil {
+			n, e := l.out.Write(p)
+			_ = dw.SetWriteDeadline(time.Time{})
+			if errors.Is(e, os.ErrDeadlineExceeded) {
+				e = ErrWriteTimeout
+			}
+			return n, e
+		}
+	}
+	// A previous write which timed out is still blocked, drop the entry instead of piling up goroutines.
+	if l.pending != nil {
+		select {
+		case <-l.pending:
+			l.pending = nil
+		default:
+			return 0, ErrWriteTimeout
+		}
+	}
+	out, b, done := l.out, append([]byte(nil), p...), make(chan struct{})
+	var n int
+	var e error
+	go func() {
+		n, e = out.Write(b)
+		close(done)
+	}()
+	timer := time.NewTimer(l.writeTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return n, e
+	case <-timer.C:
+		l.pending = done
+		return 0, ErrWriteTimeout
+	}
+}
+
+// handleError passes a non-nil error to the error handler if one is set.
+func (l *logger) handleError(e error) {
+	if e == nil {
 		return
 	}
-	_ = l.printOut(level, fmt.Sprint(v...))
-	if level == LevelFatal {
-		os.Exit(1)
+	l.Lock()
+	h := l.onError
+	l.Unlock()
+	if h != nil {
+		l.runCallback(func() { h(e) })
 	}
 }
 
-func (l *logger) Println(level Level, v ...any) {
-	if atomic.LoadInt32(&l.level) < int32(level) {
-		if level == LevelFatal {
-			os.Exit(1)
-		}
+// shutdownTimeout bounds the draining done before the program terminates, on a fatal entry or a signal,
+// e.g. hooks posting the alert.
+const shutdownTimeout = 5 * time.Second
+
+// exitIfFatal drains the logger and calls os.Exit if the level is LevelFatal, unless fatal exits are disabled
+// or the library mode is set. Hooks having a Close method, like Webhook, are closed so that their queued
+// work, e.g. the alert of the fatal entry, is done before exiting, within shutdownTimeout.
+func (l *logger) exitIfFatal(level Level) {
+	if level == LevelFatal && atomic.LoadInt32(&l.fatalExits) != 0 && atomic.LoadInt32(&l.libraryMode) == 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		l.handleError(l.Drain(ctx))
+		cancel()
+		osExit(1)
+	}
+}
+
+func (l *logger) Print(level Level, v ...any) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
 		return
 	}
-	_ = l.printOut(level, fmt.Sprintln(v...))
-	if level == LevelFatal {
-		os.Exit(1)
+	l.handleError(l.printOut(level, nil, func(b *buffer) { fmt.Fprint(b, v...) }))
+	l.exitIfFatal(level)
+}
+
+func (l *logger) Println(level Level, v ...any) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
 	}
+	l.handleError(l.printOut(level, nil, func(b *buffer) { fmt.Fprintln(b, v...) }))
+	l.exitIfFatal(level)
 }
 
 func (l *logger) Printf(level Level, format string, v ...any) {
-	if atomic.LoadInt32(&l.level) < int32(level) {
-		if level <= LevelFatal {
-			os.Exit(1)
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
+	}
+	l.handleError(l.printOut(level, nil, func(b *buffer) { fmt.Fprintf(b, format, v...) }))
+	l.exitIfFatal(level)
+}
+
+func (l *logger) PrintTo(w io.Writer, level Level, v ...any) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
+	}
+	l.handleError(l.printTo(w, time.Time{}, level, nil, func(b *buffer) { fmt.Fprint(b, v...) }))
+	l.exitIfFatal(level)
+}
+
+func (l *logger) PrintAt(t time.Time, level Level, v ...any) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
+	}
+	l.handleError(l.printTo(nil, t, level, nil, func(b *buffer) { fmt.Fprint(b, v...) }))
+	l.exitIfFatal(level)
+}
+
+func (l *logger) PrintHex(level Level, label string, data []byte) {
+	if noLog || atomic.LoadInt32(&l.level) < int32(level) {
+		l.exitIfFatal(level)
+		return
+	}
+	l.handleError(l.printOut(level, nil, func(b *buffer) {
+		*b = append(*b, label...)
+		dump := hex.Dump(data)
+		for len(dump) > 0 {
+			i := strings.IndexByte(dump, '\n')
+			*b = append(*b, "\n  "...)
+			*b = append(*b, dump[:i]...)
+			dump = dump[i+1:]
 		}
+	}))
+	l.exitIfFatal(level)
+}
+
+func (l *logger) RecoverAndLog(level Level) {
+	r := recover()
+	if r == nil {
 		return
 	}
-	_ = l.printOut(level, fmt.Sprintf(format, v...))
-	if level <= LevelFatal {
-		os.Exit(1)
+	if first, ok := l.stackLogged(panicSite()); ok {
+		l.Printf(level, "panic: %v\n(stack trace omitted, logged at %s)", r, first.Format("15:04:05"))
+	} else {
+		l.Printf(level, "panic: %v\n%s", r, debug.Stack())
+	}
+	if atomic.LoadInt32(&l.repanic) != 0 {
+		panic(r)
 	}
 }
 
+func (l *logger) SetRepanic(repanic bool) {
+	var v int32
+	if repanic {
+		v = 1
+	}
+	atomic.StoreInt32(&l.repanic, v)
+}
+
+func (l *logger) WithLevel(level Level) ILogger {
+	l.SetLevel(level)
+	return l
+}
+
+func (l *logger) WithFlags(flags int) ILogger {
+	l.SetFlags(flags)
+	return l
+}
+
+func (l *logger) WithPrefix(prefix string) ILogger {
+	l.SetPrefix(prefix)
+	return l
+}
+
+func (l *logger) WithOutput(out io.Writer) ILogger {
+	l.SetOutput(out)
+	return l
+}
+
+func (l *logger) InheritFrom(other ILogger) {
+	src, ok := other.(*logger)
+	if !ok {
+		l.SetLevel(other.GetLevel())
+		l.SetFlags(other.GetFlags())
+		l.SetPrefix(other.GetPrefix())
+		return
+	}
+	if src == l {
+		return
+	}
+	// Take a snapshot first, so that both locks are never held together.
+	src.Lock()
+	level := Level(atomic.LoadInt32(&src.level))
+	flags, prefix, bodySep, maxLineLen, fieldOrder := src.flags, src.prefix, src.bodySep, src.maxLineLen, src.fieldOrder
+	prefixTime := src.prefixTime
+	newline, colors, maxFields, fields, version := src.newline, src.colors, src.maxFields, src.fields, src.version
+	var labels map[Level]string
+	if len(src.labels) > 0 {
+		labels = make(map[Level]string, len(src.labels))
+		for k, v := range src.labels {
+			labels[k] = v
+		}
+	}
+	var styles map[Level]levelStyle
+	if len(src.styles) > 0 {
+		styles = make(map[Level]levelStyle, len(src.styles))
+		for k, v := range src.styles {
+			styles[k] = v
+		}
+	}
+	src.Unlock()
+
+	atomic.StoreInt32(&l.level, int32(level))
+	l.Lock()
+	defer l.Unlock()
+	l.flags, l.prefix, l.bodySep, l.maxLineLen, l.fieldOrder = flags, prefix, bodySep, maxLineLen, fieldOrder
+	l.prefixTime = prefixTime
+	l.newline, l.colors, l.styles = newline, colors, styles
+	l.maxFields, l.fields, l.version = maxFields, fields, version
+	l.labels = labels
+	l.resetHeaders()
+	l.resetColors()
+}
+
 func (l *logger) Clone() ILogger {
 	l.Lock()
 	defer l.Unlock()
 	newLog := New(Level(atomic.LoadInt32(&l.level)), l.prefix, l.out, l.flags)
+	if l.prefixTime {
+		newLog.SetPrefixTemplate(l.prefix)
+	}
+	newLog.SetBodySeparator(l.bodySep)
+	newLog.SetNewline(l.newline)
+	newLog.(*logger).colors = l.colors
+	for k, v := range l.styles {
+		newLog.SetLevelStyle(k, v.sgr...)
+	}
+	newLog.SetMaxLineLength(l.maxLineLen)
+	newLog.SetFieldOrder(l.fieldOrder)
+	newLog.SetMaxFields(l.maxFields)
+	newLog.SetVersion(l.version)
+	newLog.SetIDGenerator(l.idGen)
+	newLog.(*logger).fields = l.fields
+	for k, v := range l.labels {
+		newLog.SetLevelLabel(k, v)
+	}
+	newLog.SetFatalExits(atomic.LoadInt32(&l.fatalExits) != 0)
+	newLog.SetLibraryMode(atomic.LoadInt32(&l.libraryMode) != 0)
+	newLog.SetRepanic(atomic.LoadInt32(&l.repanic) != 0)
+	newLog.SetStackDedupWindow(l.stackWindow)
+	newLog.SetErrorHandler(l.onError)
+	for _, h := range l.hooks {
+		newLog.AddHook(h)
+	}
+	for _, rule := range l.colorRules {
+		newLog.AddColorRule(rule)
+	}
+	for _, w := range l.quiet {
+		newLog.AddQuietWindow(w.start, w.end, w.levels...)
+	}
+	if l.drops != nil {
+		newLog.SetDropSummary(l.drops.interval)
+	}
+	for level, out := range l.levelOut {
+		newLog.SetLevelOutput(level, out)
+	}
+	if esc := l.escalation; esc != nil {
+		newLog.SetEscalation(esc.from, esc.to, esc.count, esc.window)
+	}
+	for _, extractor := range l.extractors {
+		newLog.AddContextExtractor(extractor)
+	}
+	newLog.SetWriteTimeout(l.writeTimeout)
+	newLog.SetWriteRetry(l.retryAttempts, l.retryBackoff)
+	if l.coarse != nil {
+		newLog.SetCoarseTime(l.coarse.resolution)
+	} else {
+		newLog.SetTimeFunc(l.now)
+	}
 	return newLog
 }
 
 func New(level Level, prefix string, out io.Writer, flags int) ILogger {
+	return newLogger(level, prefix, out, flags)
+}
+
+// newLogger does the work of New, which stays small enough to be inlined. Callers then hold an ILogger whose
+// dynamic type is known, so the compiler calls the methods directly and the variadic arguments of disabled
+// entries do not escape.
+func newLogger(level Level, prefix string, out io.Writer, flags int) *logger {
 	l := logger{
-		prefix: prefix,
-		level:  int32(level),
-		flags:  flags,
-		out:    out,
+		prefix:     prefix,
+		level:      int32(level),
+		flags:      flags,
+		out:        out,
+		fatalExits: 1,
+		now:        time.Now,
+		terminal:   isTerminal(out),
 	}
 	return &l
 }
 
 // std is the default instance created to be used out of the box.
-var std = New(LevelWarn, "", os.Stderr, 0)
+// It is a *logger, so that package-level functions call its methods directly, see newLogger.
+var std = newLogger(LevelWarn, "", os.Stderr, 0)
 
 // GetDefault returns a simple implementation of ILogger.
 // It is used when you call logger.Print etc. functions without creating an instance.
@@ -280,10 +1415,21 @@ func NewDefault() ILogger {
 	return std.Clone()
 }
 
+// FlushDefault flushes the output of the default instance, see ILogger.Flush.
+// Programs setting a buffered output to the default instance should call it before exiting, e.g. with defer.
+// Fatal entries flush the output, and close the hooks, by themselves before the program exits.
+func FlushDefault() error {
+	return std.Flush()
+}
+
 // Print writes a log entry to the output using default instance. Behaves like fmt.Print standard function.
 // It returns immediately (writing nothing) if current log level is smaller than the passed Level.
 // But if the passed Level is LevelFatal, then os.Exit will be called before return.
 func Print(level Level, v ...any) {
+	if noLog {
+		std.exitIfFatal(level)
+		return
+	}
 	std.Print(level, v...)
 }
 
@@ -291,6 +1437,10 @@ func Print(level Level, v ...any) {
 // It returns immediately (writing nothing) if current log level is smaller than the passed Level.
 // But if the passed Level is LevelFatal, then os.Exit will be called before return.
 func Printf(level Level, format string, v ...any) {
+	if noLog {
+		std.exitIfFatal(level)
+		return
+	}
 	std.Printf(level, format, v...)
 }
 
@@ -298,5 +1448,9 @@ func Printf(level Level, format string, v ...any) {
 // It returns immediately (writing nothing) if current log level is smaller than the passed Level.
 // But if the passed Level is LevelFatal, then os.Exit will be called before return.
 func Println(level Level, v ...any) {
+	if noLog {
+		std.exitIfFatal(level)
+		return
+	}
 	std.Println(level, v...)
 }
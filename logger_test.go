@@ -1,9 +1,18 @@
+//go:build !nolog
+
 package logger
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 var testLevels = map[Level]string{
@@ -57,3 +66,762 @@ func TestAll(t *testing.T) {
 		}
 	}
 }
+
+func TestLevelLabel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetLevelLabel(LevelError, "[ERROR] ")
+	for k, v := range testLevels {
+		buf.Reset()
+		l.Println(k, "Using level label")
+		out := buf.String()
+		expected := v + "/"
+		if k == LevelError {
+			expected = "[ERROR] "
+		}
+		t.Log("Got: ", out)
+		if out[:len(expected)] != expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+		}
+	}
+
+	// Clones should keep the labels, empty label restores the default letter.
+	clone := l.Clone()
+	l.SetLevelLabel(LevelError, "")
+	buf.Reset()
+	l.Println(LevelError, "Default letter again")
+	if out := buf.String(); out[:2] != "E/" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "E/", out)
+	}
+	buf.Reset()
+	clone.Println(LevelError, "Cloned label")
+	if out := buf.String(); out[:8] != "[ERROR] " {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "[ERROR] ", out)
+	}
+}
+
+// Allocation budget: an enabled entry must not allocate inside the logger (0 allocs/op as long as
+// the arguments themselves need no boxing), and a disabled entry must return without any allocation.
+
+func BenchmarkPrintln(b *testing.B) {
+	l := New(LevelTrace, "BENCH", io.Discard, 0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Println(LevelInfo, "Benchmark entry", 42)
+	}
+}
+
+func BenchmarkPrintfDisabled(b *testing.B) {
+	l := New(LevelWarn, "BENCH", io.Discard, 0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Printf(LevelDebug, "Benchmark entry %d", 42)
+	}
+}
+
+func BenchmarkColored(b *testing.B) {
+	l := New(LevelTrace, "BENCH", io.Discard, FlagColorMode)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Println(LevelError, "Benchmark entry", 42)
+	}
+}
+
+func TestAllocationBudget(t *testing.T) {
+	// The budget holds through ILogger, as callers use it.
+	l := New(LevelWarn, "ALLOC", io.Discard, FlagColorMode)
+	l.Println(LevelError, "Warm up buffer")
+	if n := testing.AllocsPerRun(100, func() { l.Println(LevelError, "Enabled entry", 42) }); n != 0 {
+		t.Errorf("Allocation budget exceeded,\n\texpected: 0 allocs\n\tgot: %v allocs", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { l.Printf(LevelDebug, "Disabled entry %d", 42) }); n != 0 {
+		t.Errorf("Allocation budget exceeded,\n\texpected: 0 allocs\n\tgot: %v allocs", n)
+	}
+
+	// And through the package-level functions.
+	level := std.GetLevel()
+	defer std.SetLevel(level)
+	std.SetLevel(LevelWarn)
+	for name, disabled := range map[string]func(){
+		"Print":   func() { Print(LevelDebug, "Disabled entry ", 42) },
+		"Printf":  func() { Printf(LevelDebug, "Disabled entry %d", 42) },
+		"Println": func() { Println(LevelDebug, "Disabled entry", 42) },
+	} {
+		if n := testing.AllocsPerRun(100, disabled); n != 0 {
+			t.Errorf("Allocation budget exceeded for %s,\n\texpected: 0 allocs\n\tgot: %v allocs", name, n)
+		}
+	}
+}
+
+func TestNumericLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagNumericLevel)
+	for k := range testLevels {
+		buf.Reset()
+		l.Println(k, "Using numeric level")
+		out := buf.String()
+		expected := fmt.Sprintf("%d/", k)
+		t.Log("Got: ", out)
+		if out[:len(expected)] != expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+		}
+	}
+
+	// Numeric level must compose with colors.
+	l.SetFlags(FlagNumericLevel | FlagColorMode)
+	buf.Reset()
+	l.Println(LevelError, "Using numeric level with color")
+	out := buf.String()
+	expected := string(levelColors[LevelError]) + fmt.Sprintf("%d/", LevelError)
+	t.Log("Got: ", out)
+	if out[:len(expected)] != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, out)
+	}
+}
+
+func TestFatalExits(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelFatal, "", buf, 0)
+	l.SetFatalExits(false)
+	l.Println(LevelFatal, "Fatal entry without exit")
+	out := buf.String()
+	expected := "F/"
+	t.Log("Got: ", out)
+	if len(out) < len(expected) || out[:len(expected)] != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+
+	// Disabled fatal entries must not exit either.
+	l.SetLevel(LevelQuiet)
+	l.Print(LevelFatal, "Quiet fatal entry")
+	l.Printf(LevelFatal, "Quiet fatal entry %d", 2)
+	l.Clone().Println(LevelFatal, "Cloned fatal entry")
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	func() {
+		defer l.RecoverAndLog(LevelError)
+		panic("something went wrong")
+	}()
+	out := buf.String()
+	expected := "panic: something went wrong\n"
+	t.Log("Got: ", out)
+	if len(out) < 13+len(expected) || out[:2] != "E/" || out[13:13+len(expected)] != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+	if !strings.Contains(out, "TestRecoverAndLog") {
+		t.Errorf("Stack trace is missing,\n\tgot: %s", out)
+	}
+
+	// With repanic the panic must continue after logging.
+	buf.Reset()
+	l.SetRepanic(true)
+	var recovered any
+	func() {
+		defer func() { recovered = recover() }()
+		defer l.RecoverAndLog(LevelError)
+		panic("repanic me")
+	}()
+	if recovered != "repanic me" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %v", "repanic me", recovered)
+	}
+	if !strings.Contains(buf.String(), "panic: repanic me") {
+		t.Errorf("Panic was not logged,\n\tgot: %s", buf.String())
+	}
+}
+
+func TestPrintHex(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelDebug, "", buf, 0)
+	l.PrintHex(LevelDebug, "Packet:", []byte("GoLogger hex dump"))
+	out := buf.String()[13:]
+	expected := "Packet:\n" +
+		"  00000000  47 6f 4c 6f 67 67 65 72  20 68 65 78 20 64 75 6d  |GoLogger hex dum|\n" +
+		"  00000010  70                                                |p|\n"
+	t.Log("Got: ", out)
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+
+	buf.Reset()
+	l.PrintHex(LevelTrace, "Hidden:", []byte{1, 2, 3})
+	if buf.Len() != 0 {
+		t.Errorf("Entry above current level was written: %s", buf.String())
+	}
+}
+
+// slowWriter blocks every write until release is closed.
+type slowWriter struct {
+	release chan struct{}
+	bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return w.Buffer.Write(p)
+}
+
+func TestWriteTimeout(t *testing.T) {
+	w := &slowWriter{release: make(chan struct{})}
+	l := New(LevelTrace, "", w, 0)
+	l.SetWriteTimeout(10 * time.Millisecond)
+	var errs []error
+	l.SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	start := time.Now()
+	l.Println(LevelInfo, "Blocked entry")
+	l.Println(LevelInfo, "Dropped entry")
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Write timeout did not fire, logging took %v", d)
+	}
+	if len(errs) != 2 || errs[0] != ErrWriteTimeout || errs[1] != ErrWriteTimeout {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", []error{ErrWriteTimeout, ErrWriteTimeout}, errs)
+	}
+
+	// Once the writer recovers, entries are written again.
+	close(w.release)
+	for i := 0; i < 100; i++ {
+		n := len(errs)
+		l.Println(LevelInfo, "Recovered entry")
+		if len(errs) == n {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	if len(lines) != 2 || lines[0][13:] != "Blocked entry" || lines[1][13:] != "Recovered entry" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "Blocked entry, Recovered entry", lines)
+	}
+}
+
+func TestWriteDeadline(t *testing.T) {
+	// Nothing reads the other end of the pipe yet, so writes block until their deadline.
+	conn, peer := net.Pipe()
+	defer conn.Close()
+	defer peer.Close()
+	l := New(LevelTrace, "", conn, 0)
+	l.SetWriteTimeout(10 * time.Millisecond)
+	l.SetDropSummary(time.Hour)
+	defer l.SetDropSummary(0)
+	var errs []error
+	l.SetErrorHandler(func(err error) { errs = append(errs, err) })
+	l.Println(LevelInfo, "Dropped entry")
+	if len(errs) != 1 || errs[0] != ErrWriteTimeout {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", []error{ErrWriteTimeout}, errs)
+	}
+	l.(*logger).Lock()
+	dropped := l.(*logger).drops.counts[LevelInfo]
+	l.(*logger).Unlock()
+	if dropped != 1 {
+		t.Errorf("Pattern mismatch,\n\texpected: 1 dropped entry\n\tgot: %d", dropped)
+	}
+
+	// The deadline was cleared, later writes do not time out.
+	time.Sleep(20 * time.Millisecond)
+	go func() { _, _ = io.Copy(io.Discard, peer) }()
+	if _, err := conn.Write([]byte("Direct write\n")); err != nil {
+		t.Errorf("Deadline was not cleared: %v", err)
+	}
+}
+
+// panicWriter panics on the first write, then writes to the buffer.
+type panicWriter struct {
+	panicked bool
+	bytes.Buffer
+}
+
+func (w *panicWriter) Write(p []byte) (int, error) {
+	if !w.panicked {
+		w.panicked = true
+		panic("write failed")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestRecoveredWriterPanic(t *testing.T) {
+	w := new(panicWriter)
+	l := New(LevelTrace, "", w, 0)
+	func() {
+		defer func() { _ = recover() }()
+		l.Println(LevelInfo, "Panicking entry")
+	}()
+	done := make(chan struct{})
+	go func() {
+		l.Println(LevelInfo, "Entry after the panic")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Logger stayed locked after a recovered panic")
+	}
+	if out := w.String(); len(out) < 13 || out[13:] != "Entry after the panic\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "Entry after the panic\n", out)
+	}
+}
+
+// flakyWriter fails the first failures writes with err, writing half of p, then succeeds.
+type flakyWriter struct {
+	failures int
+	err      error
+	bytes.Buffer
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if w.failures > 0 {
+		w.failures--
+		n, _ := w.Buffer.Write(p[:len(p)/2])
+		return n, w.err
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestWriteRetry(t *testing.T) {
+	w := &flakyWriter{failures: 2, err: errors.New("connection reset")}
+	l := New(LevelTrace, "", w, 0)
+	l.SetWriteRetry(3, time.Millisecond)
+	var errs []error
+	l.SetErrorHandler(func(err error) { errs = append(errs, err) })
+	l.Println(LevelInfo, "Eventually written entry")
+	if out := w.String(); len(errs) != 0 || len(out) < 13 || out[13:] != "Eventually written entry\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %q, errors: %v", "Eventually written entry", out, errs)
+	}
+
+	// Permanent errors are not retried, running out of attempts reports the last error.
+	for _, test := range []struct {
+		err       error
+		remaining int
+	}{{os.ErrClosed, 4}, {errors.New("connection reset"), 1}} {
+		w = &flakyWriter{failures: 5, err: test.err}
+		l.SetOutput(w)
+		errs = nil
+		l.Println(LevelInfo, "Lost entry")
+		if len(errs) != 1 || errs[0] != test.err || w.failures != test.remaining {
+			t.Errorf("Pattern mismatch,\n\texpected: %v after %d attempts\n\tgot: %v after %d attempts",
+				test.err, 5-test.remaining, errs, 5-w.failures)
+		}
+	}
+}
+
+func TestBodySeparator(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "SEP", buf, 0)
+	l.SetBodySeparator("\t")
+	l.Println(LevelInfo, "Tab separated")
+	out := buf.String()
+	expected := "SEP: \tTab separated\n"
+	t.Log("Got: ", out)
+	if out[11:] != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, out)
+	}
+}
+
+func TestChaining(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelQuiet, "", nil, 0).WithOutput(buf).WithLevel(LevelDebug).WithFlags(FlagNumericLevel).WithPrefix("CHAIN")
+	if l.GetOutput() != buf || l.GetLevel() != LevelDebug || l.GetFlags() != FlagNumericLevel || l.GetPrefix() != "CHAIN" {
+		t.Errorf("Chained configuration was not applied")
+	}
+	l.Println(LevelDebug, "Chained")
+	out := buf.String()
+	expected := "5/"
+	t.Log("Got: ", out)
+	if out[:2] != expected || out[11:] != "CHAIN: Chained\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+}
+
+func TestPrintTo(t *testing.T) {
+	buf, audit := new(bytes.Buffer), new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.PrintTo(audit, LevelWarn, "Audit entry ", 1)
+	out := audit.String()
+	expected := "Audit entry 1\n"
+	t.Log("Got: ", out)
+	if out[:2] != "W/" || out[13:] != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Entry leaked to the default output: %s", buf.String())
+	}
+	l.SetLevel(LevelInfo)
+	l.PrintTo(audit, LevelDebug, "Hidden")
+	if audit.String() != out {
+		t.Errorf("Entry above current level was written: %s", audit.String())
+	}
+}
+
+func TestIndentMultiline(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagIndentMultiline)
+	l.Println(LevelError, "first line\nsecond line")
+	lines := strings.Split(buf.String(), "\n")
+	expected := []string{"E/", "E| second line", ""}
+	t.Log("Got: ", lines)
+	if len(lines) != 3 || lines[0][:2] != expected[0] || lines[0][13:] != "first line" || lines[1] != expected[1] || lines[2] != "" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, lines)
+	}
+
+	buf.Reset()
+	l.SetFlags(FlagIndentMultiline | FlagNumericLevel)
+	l.PrintFields(LevelWarn, "a\nb", Int("n", 1))
+	out := buf.String()[11:]
+	t.Log("Got: ", out)
+	if out != ": a\n3| b n=1\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", ": a\n3| b n=1\n", out)
+	}
+}
+
+func TestNilOutput(t *testing.T) {
+	l := New(LevelTrace, "", nil, FlagColorMode)
+	l.Println(LevelError, "Nowhere to go")
+	l.PrintFields(LevelInfo, "Nowhere", Int("n", 1))
+	l.SetOutput(new(bytes.Buffer))
+	l.SetOutput(nil)
+	l.Printf(LevelWarn, "Still nowhere %d", 2)
+	if l.GetOutput() != nil {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", nil, l.GetOutput())
+	}
+}
+
+func TestMaxLineLength(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetMaxLineLength(20)
+	l.Println(LevelInfo, "0123456789abcdefghijklmnopqrstuvwxyz")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	t.Log("Got: ", lines)
+	joined := ""
+	for i, line := range lines {
+		if len(line) > 20 {
+			t.Errorf("Line %d is longer than 20 bytes: %q", i, line)
+		}
+		if i < len(lines)-1 {
+			if !strings.HasSuffix(line, "\\") {
+				t.Errorf("Line %d has no continuation marker: %q", i, line)
+			}
+			line = line[:len(line)-1]
+		}
+		joined += line
+	}
+	if len(lines) != 3 || joined[13:] != "0123456789abcdefghijklmnopqrstuvwxyz" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "0123456789abcdefghijklmnopqrstuvwxyz", joined)
+	}
+
+	// Short entries are left untouched.
+	buf.Reset()
+	l.Println(LevelInfo, "short")
+	if out := buf.String(); out[13:] != "short\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "short\n", out)
+	}
+}
+
+func TestInheritFrom(t *testing.T) {
+	src := New(LevelDebug, "SRC", nil, FlagNumericLevel)
+	src.SetLevelLabel(LevelError, "[ERROR] ")
+	src.SetBodySeparator("\t")
+	buf := new(bytes.Buffer)
+	l := New(LevelWarn, "DST", buf, 0)
+	l.InheritFrom(src)
+	l.InheritFrom(l)
+	if l.GetLevel() != LevelDebug || l.GetFlags() != FlagNumericLevel || l.GetPrefix() != "SRC" || l.GetOutput() != buf {
+		t.Errorf("Configuration was not inherited: %v %v %v %v", l.GetLevel(), l.GetFlags(), l.GetPrefix(), l.GetOutput())
+	}
+	l.Println(LevelDebug, "Inherited")
+	out := buf.String()
+	expected := "SRC: \tInherited\n"
+	t.Log("Got: ", out)
+	if out[:2] != "5/" || out[11:] != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, out)
+	}
+
+	// Labels are copied, not shared.
+	src.SetLevelLabel(LevelError, "")
+	l.SetFlags(0)
+	buf.Reset()
+	l.Println(LevelError, "Label")
+	if out = buf.String(); out[:8] != "[ERROR] " {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "[ERROR] ", out)
+	}
+}
+
+func TestInheritFromFields(t *testing.T) {
+	src := New(LevelTrace, "", nil, 0).WithFields(String("component", "db"))
+	src.SetVersion("1.4.2")
+	src.SetMaxFields(1)
+	src.SetNewline(NewlineCRLF)
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	l.InheritFrom(src)
+	l.PrintFields(LevelInfo, "Inherited", Int("a", 1), Int("b", 2))
+	expected := "I/10:00:00 : Inherited a=1 component=db version=1.4.2 fields_truncated=true\r\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+}
+
+func TestPrintAt(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.PrintAt(time.Date(2021, 6, 1, 8, 15, 30, 0, time.Local), LevelInfo, "Backfilled ", "event")
+	out := buf.String()
+	expected := "I/08:15:30 : Backfilled event\n"
+	t.Log("Got: ", out)
+	if out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+}
+
+func TestLevelOrdering(t *testing.T) {
+	tests := []struct {
+		a, b              Level
+		atLeast, stricter bool
+	}{
+		{LevelFatal, LevelError, true, true},
+		{LevelError, LevelWarn, true, true},
+		{LevelWarn, LevelWarn, true, false},
+		{LevelTrace, LevelDebug, false, false},
+		{LevelInfo, LevelError, false, false},
+		{LevelQuiet, LevelFatal, false, false},
+		{LevelFatal, LevelQuiet, false, false},
+	}
+	for _, test := range tests {
+		if got := IsAtLeast(test.a, test.b); got != test.atLeast {
+			t.Errorf("Pattern mismatch for IsAtLeast(%d, %d),\n\texpected: %v\n\tgot: %v", test.a, test.b, test.atLeast, got)
+		}
+		if got := test.a.MoreSevereThan(test.b); got != test.stricter {
+			t.Errorf("Pattern mismatch for %d.MoreSevereThan(%d),\n\texpected: %v\n\tgot: %v", test.a, test.b, test.stricter, got)
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	first, second := new(bytes.Buffer), new(bytes.Buffer)
+	l := New(LevelTrace, "SEQ", first, FlagSequence)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Println(LevelInfo, "Writer", i, j)
+				if i == 0 && j == 50 {
+					// Numbering continues across outputs.
+					l.SetOutput(second)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	var last uint64
+	for _, line := range strings.Split(strings.TrimSpace(first.String()+second.String()), "\n") {
+		var seq uint64
+		if _, err := fmt.Sscanf(line[11:], "#%d SEQ: ", &seq); err != nil || seq != last+1 {
+			t.Fatalf("Pattern mismatch,\n\texpected: #%d\n\tgot: %s", last+1, line)
+		}
+		last = seq
+	}
+	if last != 800 {
+		t.Errorf("Pattern mismatch,\n\texpected: 800 entries\n\tgot: %d entries", last)
+	}
+
+	l.ResetSequence()
+	second.Reset()
+	l.Println(LevelInfo, "Restarted")
+	if out := second.String(); out[11:] != "#1 SEQ: Restarted\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "#1 SEQ: Restarted", out[11:])
+	}
+}
+
+func TestNewline(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagColorMode)
+	l.SetNewline(NewlineCRLF)
+	tests := []struct {
+		flags    int
+		msg      string
+		expected string
+	}{
+		{0, "Windows entry", "Windows entry\r\n"},
+		{0, "Kept\nas is\r\n", "Kept\nas is\r\n"},
+		{FlagNormalizeNewlines, "Multi\nline\r\nentry", "Multi\r\nline\r\nentry\r\n"},
+		{FlagNormalizeNewlines | FlagIndentMultiline, "Multi\nline", "Multi\r\nI| line\r\n"},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		l.SetFlags(test.flags)
+		l.Print(LevelInfo, test.msg)
+		if out := buf.String(); out[13:] != test.expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", test.expected, out[13:])
+		}
+	}
+
+	// Colors are reset after the terminator, like with LF.
+	buf.Reset()
+	l.SetFlags(FlagColorMode)
+	l.Print(LevelInfo, "Colored")
+	if out := buf.String(); !envNoColor && !strings.HasSuffix(out, "Colored\r\n\033[0m") {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "Colored\r\n\033[0m", out)
+	}
+
+	// Normalizing to LF removes carriage returns.
+	buf.Reset()
+	l.SetNewline(NewlineLF)
+	l.SetFlags(FlagNormalizeNewlines)
+	l.Print(LevelInfo, "From\r\nWindows\r\n")
+	if out := buf.String(); out[13:] != "From\nWindows\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "From\nWindows\n", out[13:])
+	}
+}
+
+func TestLastEntry(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "LAST", buf, 0)
+	l.Println(LevelInfo, "Forgotten")
+	if last := l.LastEntry(); last != nil {
+		t.Errorf("Entry was remembered while disabled: %q", last)
+	}
+	l.SetRememberLast(true)
+	for _, msg := range []string{"First", "Second", "Multi\nline"} {
+		buf.Reset()
+		l.Println(LevelWarn, msg)
+		if last := l.LastEntry(); string(last) != buf.String() {
+			t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", buf.String(), last)
+		}
+	}
+	// Disabled entries are not remembered.
+	l.SetLevel(LevelInfo)
+	l.Println(LevelDebug, "Hidden")
+	if last := l.LastEntry(); string(last) != buf.String() {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", buf.String(), last)
+	}
+	l.SetRememberLast(false)
+	if last := l.LastEntry(); last != nil {
+		t.Errorf("Entry was kept after disabling: %q", last)
+	}
+}
+
+func TestIToA(t *testing.T) {
+	tests := []struct {
+		i, wid   int
+		expected string
+	}{
+		{0, -1, "0"},
+		{0, 0, "0"},
+		{0, 1, "0"},
+		{0, 2, "00"},
+		{7, 2, "07"},
+		{9, 1, "9"},
+		{10, 1, "10"},
+		{10, 2, "10"},
+		{59, 2, "59"},
+		{123, 2, "123"},
+		{42, 6, "000042"},
+		{999999, 6, "999999"},
+		{1000000, 6, "1000000"},
+		{5, 9, "000000005"},
+		{999999999, 9, "999999999"},
+		{999999999, -1, "999999999"},
+		{2147483647, -1, "2147483647"},
+		{1, 20, "00000000000000000001"},
+		{1, 25, "00000000000000000001"},
+	}
+	for _, test := range tests {
+		b := []byte("x")
+		iToA(&b, test.i, test.wid)
+		if got := string(b[1:]); got != test.expected {
+			t.Errorf("Pattern mismatch for iToA(%d, %d),\n\texpected: %s\n\tgot: %s", test.i, test.wid, test.expected, got)
+		}
+	}
+}
+
+func BenchmarkIToA(b *testing.B) {
+	buf := make([]byte, 0, 32)
+	for i := 0; i < b.N; i++ {
+		buf = buf[:0]
+		iToA(&buf, 999999999, 9)
+	}
+}
+
+func TestTrimNewlines(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagTrimNewlines)
+	tests := []struct {
+		print    func()
+		expected string
+	}{
+		{func() { l.Print(LevelInfo, "Two newlines\n\n") }, "Two newlines\n"},
+		{func() { l.Println(LevelInfo, "Println adds one more\n\n") }, "Println adds one more\n"},
+		{func() { l.Printf(LevelInfo, "Windows\r\n\r\n") }, "Windows\n"},
+		{func() { l.Print(LevelInfo, "Inner\n\nkept\n") }, "Inner\n\nkept\n"},
+		{func() { l.PrintFields(LevelInfo, "With fields\n\n", Int("n", 1)) }, "With fields n=1\n"},
+		{func() { l.Print(LevelInfo, "\n\n") }, "\n"},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		test.print()
+		if out := buf.String(); out[13:] != test.expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", test.expected, out[13:])
+		}
+	}
+
+	// Without the flag, extra newlines are kept.
+	buf.Reset()
+	l.SetFlags(0)
+	l.Print(LevelInfo, "Two newlines\n\n")
+	if out := buf.String(); out[13:] != "Two newlines\n\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "Two newlines\n\n", out[13:])
+	}
+}
+
+func TestStaticHeaderReset(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "OLD", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	tests := []struct {
+		change   func()
+		expected string
+	}{
+		{func() {}, "I/10:00:00 OLD: Entry\n"},
+		{func() { l.SetPrefix("NEW") }, "I/10:00:00 NEW: Entry\n"},
+		{func() { l.SetFlags(FlagNumericLevel) }, "4/10:00:00 NEW: Entry\n"},
+		{func() { l.SetFlags(0); l.SetLevelLabel(LevelInfo, "[INFO] ") }, "[INFO] 10:00:00 NEW: Entry\n"},
+		{func() { l.SetBodySeparator("\t") }, "[INFO] 10:00:00 NEW: \tEntry\n"},
+		{func() { l.SetPrefixTemplate("{time}") }, "[INFO] 10:00:00 2022-01-01T10:00:00" + time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local).Format("Z07:00") + ": \tEntry\n"},
+		{func() { l.SetLevelLabel(LevelInfo, ""); l.SetPrefix("") }, "I/10:00:00 : \tEntry\n"},
+	}
+	for _, test := range tests {
+		test.change()
+		buf.Reset()
+		l.Println(LevelInfo, "Entry")
+		if out := buf.String(); out != test.expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", test.expected, out)
+		}
+	}
+}
+
+func BenchmarkBuildHeader(b *testing.B) {
+	l := New(LevelTrace, "BENCH", io.Discard, 0).(*logger)
+	l.SetLevelLabel(LevelInfo, "[INFO] ")
+	l.SetBodySeparator("\t")
+	now := time.Now()
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = buf[:0]
+		l.buildHeader(LevelInfo, &buf, now)
+	}
+}
+
+func BenchmarkColoredStyled(b *testing.B) {
+	l := New(LevelTrace, "BENCH", io.Discard, FlagColorMode)
+	_ = l.SetTheme(ThemeSolarized)
+	l.SetLevelStyle(LevelError, Bold, Underline)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Println(LevelError, "Benchmark entry", 42)
+	}
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"crypto/rand"
+	"net/http"
+)
+
+func (l *logger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var path string
+		if r.URL != nil {
+			path = r.URL.Path
+		}
+		child := l.WithFields(
+			String("request_id", l.newID()),
+			String("method", r.Method),
+			String("path", path),
+			String("remote_addr", r.RemoteAddr),
+		)
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), child)))
+	})
+}
+
+func (l *logger) SetIDGenerator(gen func() string) {
+	l.Lock()
+	defer l.Unlock()
+	l.idGen = gen
+}
+
+// newID returns a correlation id from the generator set by SetIDGenerator, or a random one.
+func (l *logger) newID() string {
+	l.Lock()
+	gen := l.idGen
+	l.Unlock()
+	if gen == nil {
+		return randomID()
+	}
+	return gen()
+}
+
+// base62 holds the digits of the ids returned by randomID.
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// randomID returns a random id of 16 base62 digits, about 95 bits.
+func randomID() string {
+	var id, b [16]byte
+	for n := 0; n < len(id); {
+		_, _ = rand.Read(b[:])
+		for _, c := range b {
+			// Bytes from 248 on are skipped, so that every digit is as likely.
+			if c < 248 && n < len(id) {
+				id[n] = base62[c%62]
+				n++
+			}
+		}
+	}
+	return string(id[:])
+}
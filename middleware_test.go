@@ -0,0 +1,79 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetFieldOrder(FieldOrderInsertion)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	handler := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).PrintFields(LevelInfo, "Handled", Int("user", 42))
+	}))
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest("GET", "/users/42?token=secret", nil)
+		r.RemoteAddr = "10.0.0.2:51234"
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	pattern := regexp.MustCompile(`^I/10:00:00 : Handled request_id=([0-9A-Za-z]{16}) method=GET path=/users/42 remote_addr=10.0.0.2:51234 user=42$`)
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Pattern mismatch,\n\texpected: 2 lines\n\tgot: %q", buf.String())
+	}
+	var ids []string
+	for _, line := range lines {
+		m := pattern.FindSubmatch(line)
+		if m == nil {
+			t.Fatalf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", pattern, line)
+		}
+		ids = append(ids, string(m[1]))
+	}
+	if ids[0] == ids[1] {
+		t.Errorf("Requests got the same id %s", ids[0])
+	}
+
+	// The logger itself is left unchanged.
+	buf.Reset()
+	l.Println(LevelInfo, "Parent")
+	if got := buf.String(); got != "I/10:00:00 : Parent\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "I/10:00:00 : Parent\n", got)
+	}
+}
+
+func TestIDGenerator(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	var n int
+	l.SetIDGenerator(func() string {
+		n++
+		return "req-" + strconv.Itoa(n)
+	})
+	handler := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Println(LevelInfo, "Handled")
+	}))
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	for _, id := range []string{"request_id=req-1\n", "request_id=req-2\n"} {
+		if !strings.Contains(buf.String(), id) {
+			t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", id, buf.String())
+		}
+	}
+
+	l.SetIDGenerator(nil)
+	if id := l.(*logger).newID(); !regexp.MustCompile(`^[0-9A-Za-z]{16}$`).MatchString(id) {
+		t.Errorf("Pattern mismatch,\n\texpected: 16 base62 digits\n\tgot: %q", id)
+	}
+}
@@ -0,0 +1,65 @@
+//go:build nolog
+
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Run with go test -tags nolog -bench NoLog, the tests relying on written output are built without the tag.
+
+func TestNoLog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := GetDefault()
+	out, level := l.GetOutput(), l.GetLevel()
+	defer func() {
+		l.SetOutput(out)
+		l.SetLevel(level)
+	}()
+	l.SetOutput(buf)
+	l.SetLevel(LevelTrace)
+	Println(LevelError, "Compiled out")
+	Printf(LevelWarn, "Compiled out %d", 1)
+	Print(LevelInfo, "Compiled out")
+	l.Println(LevelError, "Compiled out")
+	l.PrintFields(LevelError, "Compiled out", Int("n", 1))
+	if buf.Len() != 0 {
+		t.Errorf("Pattern mismatch,\n\texpected: no output\n\tgot: %s", buf.String())
+	}
+}
+
+func BenchmarkNoLog(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Printf(LevelError, "Compiled out %d %s", i, "entry")
+	}
+}
+
+// BenchmarkNoLogInstance measures the overhead left in the methods of an instance enabling every level.
+func BenchmarkNoLogInstance(b *testing.B) {
+	l := New(LevelTrace, "", new(bytes.Buffer), FlagPackage)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Printf(LevelError, "Compiled out %d %s", i, "entry")
+		l.PrintFields(LevelInfo, "Compiled out", Int("n", i))
+	}
+}
+
+func TestNoLogLibraryMode(t *testing.T) {
+	exit := CaptureExit()
+	defer exit.Restore()
+	std.SetLibraryMode(true)
+	defer std.SetLibraryMode(false)
+	Print(LevelFatal, "Compiled out")
+	Printf(LevelFatal, "Compiled out %d", 1)
+	Println(LevelFatal, "Compiled out")
+	if code, ok := exit.Code(); ok {
+		t.Errorf("Library mode ignored, exited with %d", code)
+	}
+	std.SetLibraryMode(false)
+	Print(LevelFatal, "Compiled out")
+	if code, ok := exit.Code(); !ok || code != 1 {
+		t.Errorf("Pattern mismatch,\n\texpected: exit 1\n\tgot: %d, %v", code, ok)
+	}
+}
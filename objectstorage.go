@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"sync"
+)
+
+// ObjectStorageWriter is an io.Writer keeping entries in memory and uploading them as a single object
+// on Flush or Close. It suits batch jobs shipping their logs to S3 like storages at the end of a run.
+// The upload function is provided by the user, so no cloud SDK is required by this package.
+type ObjectStorageWriter struct {
+	upload func(data []byte) error
+	buf    bytes.Buffer
+	closed bool
+	sync.Mutex
+}
+
+// NewObjectStorageWriter returns an ObjectStorageWriter uploading buffered content with upload.
+func NewObjectStorageWriter(upload func(data []byte) error) *ObjectStorageWriter {
+	return &ObjectStorageWriter{upload: upload}
+}
+
+// Write appends p to the in-memory buffer.
+func (w *ObjectStorageWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	return w.buf.Write(p)
+}
+
+// Flush uploads the content buffered since the last successful Flush as one object.
+// Nothing is uploaded if the buffer is empty. On failure the content is kept for the next attempt.
+func (w *ObjectStorageWriter) Flush() error {
+	w.Lock()
+	defer w.Unlock()
+	return w.flush()
+}
+
+func (w *ObjectStorageWriter) flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	if err := w.upload(w.buf.Bytes()); err != nil {
+		return err
+	}
+	w.buf.Reset()
+	return nil
+}
+
+// Close flushes the buffered content, subsequent writes fail with os.ErrClosed.
+func (w *ObjectStorageWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	w.closed = true
+	return w.flush()
+}
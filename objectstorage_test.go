@@ -0,0 +1,49 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestObjectStorageWriter(t *testing.T) {
+	var uploads []string
+	fail := true
+	w := NewObjectStorageWriter(func(data []byte) error {
+		if fail {
+			fail = false
+			return errors.New("network down")
+		}
+		uploads = append(uploads, string(data))
+		return nil
+	})
+	l := New(LevelTrace, "", w, 0)
+	l.Println(LevelInfo, "first")
+	l.Println(LevelInfo, "second")
+	if len(uploads) != 0 {
+		t.Errorf("Content was uploaded before close: %v", uploads)
+	}
+
+	// A failed upload keeps the content for the next attempt.
+	if err := w.Flush(); err == nil {
+		t.Errorf("Upload error was not returned")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 1 {
+		t.Fatalf("Pattern mismatch,\n\texpected: 1 upload\n\tgot: %d uploads", len(uploads))
+	}
+	lines := strings.Split(uploads[0], "\n")
+	if len(lines) != 3 || lines[0][13:] != "first" || lines[1][13:] != "second" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %q", "first, second", lines)
+	}
+	if _, err := w.Write([]byte("late\n")); err == nil {
+		t.Errorf("Write after close did not fail")
+	}
+}
@@ -0,0 +1,62 @@
+//go:build darwin
+
+package logger
+
+import (
+	"bytes"
+	"log/syslog"
+)
+
+// These syslog severities are used for every Level, the system forwards them to the unified log (os_log).
+var osLogSeverities = map[Level]syslog.Priority{
+	LevelFatal: syslog.LOG_CRIT,
+	LevelError: syslog.LOG_ERR,
+	LevelWarn:  syslog.LOG_WARNING,
+	LevelInfo:  syslog.LOG_INFO,
+	LevelDebug: syslog.LOG_DEBUG,
+	LevelTrace: syslog.LOG_DEBUG,
+}
+
+// OSLogWriter writes log entries to the native system log: the unified log on macOS (through syslog)
+// and the Event Log on Windows. Levels are mapped to native severities.
+// On other platforms it discards everything, use JournaldWriter on Linux.
+type OSLogWriter struct {
+	w *syslog.Writer
+}
+
+// NewOSLogWriter returns an OSLogWriter tagging entries with source.
+func NewOSLogWriter(source string) (*OSLogWriter, error) {
+	w, err := syslog.New(syslog.LOG_USER|syslog.LOG_INFO, source)
+	if err != nil {
+		return nil, err
+	}
+	return &OSLogWriter{w: w}, nil
+}
+
+// Write writes p with the severity of LevelInfo.
+func (w *OSLogWriter) Write(p []byte) (int, error) {
+	if err := w.WriteEntry(&Entry{Level: LevelInfo, Message: string(bytes.TrimSuffix(p, []byte{'\n'}))}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteEntry writes the message of the Entry with its mapped severity.
+func (w *OSLogWriter) WriteEntry(e *Entry) error {
+	switch osLogSeverities[e.Level] {
+	case syslog.LOG_CRIT:
+		return w.w.Crit(e.Message)
+	case syslog.LOG_ERR:
+		return w.w.Err(e.Message)
+	case syslog.LOG_WARNING:
+		return w.w.Warning(e.Message)
+	case syslog.LOG_DEBUG:
+		return w.w.Debug(e.Message)
+	}
+	return w.w.Info(e.Message)
+}
+
+// Close closes the connection to the system log.
+func (w *OSLogWriter) Close() error {
+	return w.w.Close()
+}
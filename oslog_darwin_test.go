@@ -0,0 +1,22 @@
+//go:build darwin
+
+package logger
+
+import (
+	"log/syslog"
+	"testing"
+)
+
+func TestOSLogSeverities(t *testing.T) {
+	for level, expected := range map[Level]syslog.Priority{
+		LevelFatal: syslog.LOG_CRIT,
+		LevelError: syslog.LOG_ERR,
+		LevelWarn:  syslog.LOG_WARNING,
+		LevelInfo:  syslog.LOG_INFO,
+		LevelTrace: syslog.LOG_DEBUG,
+	} {
+		if got := osLogSeverities[level]; got != expected {
+			t.Errorf("Pattern mismatch for level %d,\n\texpected: %v\n\tgot: %v", level, expected, got)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !darwin && !windows
+
+package logger
+
+// OSLogWriter writes log entries to the native system log: the unified log on macOS (through syslog)
+// and the Event Log on Windows. Levels are mapped to native severities.
+// On other platforms it discards everything, use JournaldWriter on Linux.
+type OSLogWriter struct{}
+
+// NewOSLogWriter returns an OSLogWriter, which discards everything on this platform.
+func NewOSLogWriter(source string) (*OSLogWriter, error) {
+	return &OSLogWriter{}, nil
+}
+
+// Write discards p.
+func (w *OSLogWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteEntry discards the Entry.
+func (w *OSLogWriter) WriteEntry(e *Entry) error {
+	return nil
+}
+
+// Close does nothing.
+func (w *OSLogWriter) Close() error {
+	return nil
+}
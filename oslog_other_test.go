@@ -0,0 +1,20 @@
+//go:build !darwin && !windows
+
+package logger
+
+import "testing"
+
+func TestOSLogWriterFallback(t *testing.T) {
+	w, err := NewOSLogWriter("gologger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(LevelTrace, "", w, 0)
+	l.Println(LevelError, "Discarded")
+	if n, err := w.Write([]byte("Discarded\n")); n != 10 || err != nil {
+		t.Errorf("Pattern mismatch,\n\texpected: 10 <nil>\n\tgot: %d %v", n, err)
+	}
+	if err = w.Close(); err != nil {
+		t.Error(err)
+	}
+}
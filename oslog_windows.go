@@ -0,0 +1,84 @@
+//go:build windows
+
+package logger
+
+import (
+	"bytes"
+	"syscall"
+	"unsafe"
+)
+
+// These are the event types of ReportEventW.
+const (
+	eventLogErrorType       = 0x0001
+	eventLogWarningType     = 0x0002
+	eventLogInformationType = 0x0004
+)
+
+// These event types are used for every Level.
+var osLogSeverities = map[Level]uint16{
+	LevelFatal: eventLogErrorType,
+	LevelError: eventLogErrorType,
+	LevelWarn:  eventLogWarningType,
+	LevelInfo:  eventLogInformationType,
+	LevelDebug: eventLogInformationType,
+	LevelTrace: eventLogInformationType,
+}
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSourceW  = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEventW          = advapi32.NewProc("ReportEventW")
+)
+
+// OSLogWriter writes log entries to the native system log: the unified log on macOS (through syslog)
+// and the Event Log on Windows. Levels are mapped to native severities.
+// On other platforms it discards everything, use JournaldWriter on Linux.
+type OSLogWriter struct {
+	handle uintptr
+}
+
+// NewOSLogWriter returns an OSLogWriter reporting events from source.
+func NewOSLogWriter(source string) (*OSLogWriter, error) {
+	name, err := syscall.UTF16PtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+	h, _, err := procRegisterEventSourceW.Call(0, uintptr(unsafe.Pointer(name)))
+	if h == 0 {
+		return nil, err
+	}
+	return &OSLogWriter{handle: h}, nil
+}
+
+// Write writes p with the event type of LevelInfo.
+func (w *OSLogWriter) Write(p []byte) (int, error) {
+	if err := w.WriteEntry(&Entry{Level: LevelInfo, Message: string(bytes.TrimSuffix(p, []byte{'\n'}))}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteEntry reports the message of the Entry as an event of its mapped type.
+func (w *OSLogWriter) WriteEntry(e *Entry) error {
+	msg, err := syscall.UTF16PtrFromString(e.Message)
+	if err != nil {
+		return err
+	}
+	strs := [1]*uint16{msg}
+	r, _, err := procReportEventW.Call(w.handle, uintptr(osLogSeverities[e.Level]), 0, 1, 0, 1, 0,
+		uintptr(unsafe.Pointer(&strs[0])), 0)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// Close deregisters the event source.
+func (w *OSLogWriter) Close() error {
+	if r, _, err := procDeregisterEventSource.Call(w.handle); r == 0 {
+		return err
+	}
+	return nil
+}
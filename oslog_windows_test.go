@@ -0,0 +1,19 @@
+//go:build windows
+
+package logger
+
+import "testing"
+
+func TestOSLogSeverities(t *testing.T) {
+	for level, expected := range map[Level]uint16{
+		LevelFatal: eventLogErrorType,
+		LevelError: eventLogErrorType,
+		LevelWarn:  eventLogWarningType,
+		LevelInfo:  eventLogInformationType,
+		LevelTrace: eventLogInformationType,
+	} {
+		if got := osLogSeverities[level]; got != expected {
+			t.Errorf("Pattern mismatch for level %d,\n\texpected: %v\n\tgot: %v", level, expected, got)
+		}
+	}
+}
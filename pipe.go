@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// ErrNoCommand is returned by NewPipeWriter and NewPipeLogger when no command is given.
+var ErrNoCommand = errors.New("logger: no command given")
+
+// PipeWriter is an io.Writer writing to the standard input of a process, e.g. an external formatter or
+// shipper. The process shares the standard output and error of the program.
+// Close it when done, so that the process gets the end of its input and is waited for.
+type PipeWriter struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	sync.Mutex
+}
+
+// NewPipeWriter starts the command, its name followed by its arguments, and returns a PipeWriter writing to it.
+func NewPipeWriter(cmd ...string) (*PipeWriter, error) {
+	if len(cmd) == 0 {
+		return nil, ErrNoCommand
+	}
+	c := exec.Command(cmd[0], cmd[1:]...)
+	c.Stdout, c.Stderr = os.Stdout, os.Stderr
+	stdin, err := c.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	return &PipeWriter{cmd: c, stdin: stdin}, nil
+}
+
+// NewPipeLogger returns a clone of the default instance writing to the standard input of the command,
+// see NewPipeWriter. ILogger.Close closes the pipe and waits for the process.
+func NewPipeLogger(cmd ...string) (ILogger, error) {
+	w, err := NewPipeWriter(cmd...)
+	if err != nil {
+		return nil, err
+	}
+	l := NewDefault().(*logger)
+	l.SetOutput(w)
+	l.closer = w
+	return l, nil
+}
+
+// Write writes p to the input of the process.
+func (w *PipeWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.stdin == nil {
+		return 0, os.ErrClosed
+	}
+	return w.stdin.Write(p)
+}
+
+// Close closes the input of the process and waits for it to exit, returning its error if it failed.
+// Subsequent writes fail with os.ErrClosed.
+func (w *PipeWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	if w.stdin == nil {
+		return nil
+	}
+	err := w.stdin.Close()
+	w.stdin = nil
+	if e := w.cmd.Wait(); e != nil {
+		err = e
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestPipeLogger runs itself as the process of the pipe, copying its input to a file like cat does.
+func TestPipeLogger(t *testing.T) {
+	if path := os.Getenv("GOLOGGER_PIPE_FILE"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			os.Exit(3)
+		}
+		if _, err := io.Copy(f, os.Stdin); err != nil {
+			os.Exit(4)
+		}
+		_ = f.Close()
+		os.Exit(0)
+	}
+
+	if _, err := NewPipeLogger(); err != ErrNoCommand {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", ErrNoCommand, err)
+	}
+	path := filepath.Join(t.TempDir(), "pipe.log")
+	t.Setenv("GOLOGGER_PIPE_FILE", path)
+	l, err := NewPipeLogger(os.Args[0], "-test.run", "^TestPipeLogger$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetLevel(LevelInfo)
+	l.Println(LevelInfo, "First")
+	l.PrintFields(LevelWarn, "Second", Int("n", 2))
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Close waited for the process, so the file is complete.
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "First") || !strings.HasSuffix(lines[1], "Second n=2") {
+		t.Errorf("Pattern mismatch,\n\texpected: First and Second n=2\n\tgot: %q", b)
+	}
+	if _, err := l.GetOutput().Write([]byte("Closed\n")); err != os.ErrClosed {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", os.ErrClosed, err)
+	}
+}
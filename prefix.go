@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timePlaceholder is the placeholder of prefix templates expanded for every entry.
+const timePlaceholder = "{time}"
+
+// expandPrefixTemplate replaces the placeholders resolved once, {pid} and {host}, in tmpl.
+func expandPrefixTemplate(tmpl string) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+	return strings.NewReplacer("{pid}", strconv.Itoa(os.Getpid()), "{host}", hostname()).Replace(tmpl)
+}
+
+// hostname returns the host name reported by the kernel, or "unknown".
+func hostname() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return host
+}
+
+func (l *logger) SetPrefixTemplate(tmpl string) {
+	prefix := expandPrefixTemplate(tmpl)
+	l.Lock()
+	defer l.Unlock()
+	l.prefix = prefix
+	l.prefixTime = strings.Contains(prefix, timePlaceholder)
+	l.resetHeaders()
+}
+
+// appendPrefix appends the prefix to buf, expanding {time} to t if the prefix is a template.
+// It must be called with the lock held.
+func (l *logger) appendPrefix(buf *[]byte, t time.Time) {
+	if !l.prefixTime {
+		*buf = append(*buf, l.prefix...)
+		return
+	}
+	prefix := l.prefix
+	for {
+		i := strings.Index(prefix, timePlaceholder)
+		if i < 0 {
+			break
+		}
+		*buf = append(*buf, prefix[:i]...)
+		*buf = t.AppendFormat(*buf, time.RFC3339)
+		prefix = prefix[i+len(timePlaceholder):]
+	}
+	*buf = append(*buf, prefix...)
+}
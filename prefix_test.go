@@ -0,0 +1,43 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPrefixTemplate(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 2, 13, 4, 5, 0, time.UTC) })
+	host, _ := os.Hostname()
+	l.SetPrefixTemplate("app[{pid}]@{host}")
+	l.Println(LevelInfo, "Started")
+	expected := "I/13:04:05 app[" + strconv.Itoa(os.Getpid()) + "]@" + host + ": Started\n"
+	if out := buf.String(); out != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+	}
+
+	// {time} is expanded for every entry, also in clones.
+	l.SetPrefixTemplate("{time} {unknown}")
+	for _, l := range []ILogger{l, l.Clone()} {
+		buf.Reset()
+		l.Println(LevelInfo, "Timed")
+		expected = "I/13:04:05 2022-01-02T13:04:05Z {unknown}: Timed\n"
+		if out := buf.String(); out != expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+		}
+	}
+
+	// Plain prefixes are never expanded.
+	buf.Reset()
+	l.SetPrefix("{time}")
+	l.Println(LevelInfo, "Literal")
+	if out := buf.String(); out[11:] != "{time}: Literal\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", "{time}: Literal", out[11:])
+	}
+}
@@ -0,0 +1,247 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrInvalidProto is returned by a ProtoReader when a record is too large or can not be decoded.
+var ErrInvalidProto = errors.New("logger: invalid protobuf record")
+
+// Protobuf wire types used by the schema in entry.proto.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// ProtoWriter writes log entries as protobuf records following the Entry message of entry.proto,
+// each one preceded by its size as a varint so that they can be streamed. Read them with a ProtoReader.
+type ProtoWriter struct {
+	w    io.Writer
+	buf  []byte
+	body []byte
+	sync.Mutex
+}
+
+// NewProtoWriter returns a ProtoWriter writing records to w.
+func NewProtoWriter(w io.Writer) *ProtoWriter {
+	return &ProtoWriter{w: w}
+}
+
+// Write writes p as the message of a record with LevelInfo.
+// It is used when the writer is wrapped by another io.Writer, the logger itself calls WriteEntry.
+func (w *ProtoWriter) Write(p []byte) (int, error) {
+	err := w.WriteEntry(&Entry{Level: LevelInfo, Message: string(bytes.TrimSuffix(p, []byte{'\n'}))})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteEntry writes the Entry as a single record.
+func (w *ProtoWriter) WriteEntry(e *Entry) error {
+	w.Lock()
+	defer w.Unlock()
+	w.body = appendProtoEntry(w.body[:0], e)
+	w.buf = appendUvarint(w.buf[:0], uint64(len(w.body)))
+	w.buf = append(w.buf, w.body...)
+	_, err := w.w.Write(w.buf)
+	return err
+}
+
+// appendUvarint appends v as a varint, like binary.PutUvarint writes it.
+func appendUvarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendTag(b []byte, num int, wire int) []byte {
+	return appendUvarint(b, uint64(num<<3|wire))
+}
+
+func appendProtoBytes(b []byte, num int, s string) []byte {
+	b = appendTag(b, num, wireBytes)
+	b = appendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// appendProtoEntry appends the Entry message, leaving out the fields having their default value.
+func appendProtoEntry(b []byte, e *Entry) []byte {
+	if e.Level != 0 {
+		b = appendTag(b, 1, wireVarint)
+		b = appendUvarint(b, uint64(e.Level))
+	}
+	if !e.Time.IsZero() {
+		b = appendTag(b, 2, wireVarint)
+		b = appendUvarint(b, uint64(e.Time.UnixNano()))
+	}
+	if e.Message != "" {
+		b = appendProtoBytes(b, 3, e.Message)
+	}
+	var field []byte
+	for _, f := range e.Fields {
+		field = appendProtoField(field[:0], f)
+		b = appendTag(b, 4, wireBytes)
+		b = appendUvarint(b, uint64(len(field)))
+		b = append(b, field...)
+	}
+	if e.Sequence != 0 {
+		b = appendTag(b, 5, wireVarint)
+		b = appendUvarint(b, e.Sequence)
+	}
+	return b
+}
+
+// appendProtoField appends the Field message. Errors and other values are sent as their rendered text.
+func appendProtoField(b []byte, f Field) []byte {
+	b = appendProtoBytes(b, 1, f.Key)
+	switch f.kind {
+	case kindString:
+		return appendProtoBytes(b, 2, f.str)
+	case kindInt:
+		b = appendTag(b, 3, wireVarint)
+		return appendUvarint(b, uint64(f.num<<1^f.num>>63))
+	case kindBool:
+		b = appendTag(b, 4, wireVarint)
+		return appendUvarint(b, uint64(f.num))
+	case kindError:
+		if f.err == nil {
+			return appendProtoBytes(b, 2, "<nil>")
+		}
+		return appendProtoBytes(b, 2, errorString(f.err))
+	}
+	return appendProtoBytes(b, 2, string(appendAny(nil, f.any)))
+}
+
+// ProtoReader reads the records written by a ProtoWriter.
+type ProtoReader struct {
+	r      *bufio.Reader
+	record []byte
+}
+
+// NewProtoReader returns a ProtoReader reading records from r.
+func NewProtoReader(r io.Reader) *ProtoReader {
+	return &ProtoReader{r: bufio.NewReader(r)}
+}
+
+// ReadEntry reads and decodes the next record. Errors and other values of fields are decoded as String fields.
+// It returns io.EOF after the last record, or io.ErrUnexpectedEOF if the last record is truncated.
+func (r *ProtoReader) ReadEntry() (*Entry, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxRecordLen {
+		return nil, ErrInvalidProto
+	}
+	if cap(r.record) < int(n) {
+		r.record = make([]byte, n)
+	}
+	record := r.record[:n]
+	if _, err := io.ReadFull(r.r, record); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	e := new(Entry)
+	err = decodeProto(record, func(num, wire int, v uint64, data []byte) bool {
+		switch {
+		case num == 1 && wire == wireVarint:
+			e.Level = Level(v)
+		case num == 2 && wire == wireVarint:
+			e.Time = time.Unix(0, int64(v))
+		case num == 3 && wire == wireBytes:
+			e.Message = string(data)
+		case num == 4 && wire == wireBytes:
+			f, ok := decodeProtoField(data)
+			if !ok {
+				return false
+			}
+			e.Fields = append(e.Fields, f)
+		case num == 5 && wire == wireVarint:
+			e.Sequence = v
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// decodeProtoField decodes a Field message, it tells whether it is valid.
+func decodeProtoField(b []byte) (Field, bool) {
+	var f Field
+	err := decodeProto(b, func(num, wire int, v uint64, data []byte) bool {
+		switch {
+		case num == 1 && wire == wireBytes:
+			f.Key = string(data)
+		case num == 2 && wire == wireBytes:
+			f.kind, f.str = kindString, string(data)
+		case num == 3 && wire == wireVarint:
+			f.kind, f.num = kindInt, int64(v>>1)^-int64(v&1)
+		case num == 4 && wire == wireVarint:
+			f.kind, f.num = kindBool, 0
+			if v != 0 {
+				f.num = 1
+			}
+		}
+		return true
+	})
+	return f, err == nil
+}
+
+// decodeProto calls fn for every field of the message in b, with its value for varints or its data for
+// length-delimited fields. Fixed size fields are skipped. It stops early if fn returns false.
+func decodeProto(b []byte, fn func(num, wire int, v uint64, data []byte) bool) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return ErrInvalidProto
+		}
+		b = b[n:]
+		num, wire := int(tag>>3), int(tag&7)
+		var v uint64
+		var data []byte
+		switch wire {
+		case wireVarint:
+			if v, n = binary.Uvarint(b); n <= 0 {
+				return ErrInvalidProto
+			}
+			b = b[n:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return ErrInvalidProto
+			}
+			data, b = b[n:n+int(size)], b[n+int(size):]
+		case wireFixed64, wireFixed32:
+			size := 8
+			if wire == wireFixed32 {
+				size = 4
+			}
+			if len(b) < size {
+				return ErrInvalidProto
+			}
+			b = b[size:]
+			continue
+		default:
+			return ErrInvalidProto
+		}
+		if !fn(num, wire, v, data) {
+			return ErrInvalidProto
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProtoWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", NewProtoWriter(buf), FlagSequence)
+	now := time.Date(2022, 1, 1, 10, 0, 0, 123456789, time.UTC)
+	l.SetTimeFunc(func() time.Time { return now })
+	l.PrintFields(LevelError, "Query failed", String("db", "main db"), Int("rows", -3), Bool("retry", false), Err(errors.New("timeout")))
+	l.Println(LevelInfo, "Done")
+
+	expected := []Entry{
+		{Time: now, Level: LevelError, Message: "Query failed", Sequence: 1, Fields: []Field{
+			String("db", "main db"), String("error", "timeout"), Bool("retry", false), Int("rows", -3),
+		}},
+		{Time: now, Level: LevelInfo, Message: "Done", Sequence: 2},
+	}
+	r := NewProtoReader(buf)
+	for _, e := range expected {
+		got, err := r.ReadEntry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Time.Equal(e.Time) || got.Level != e.Level || got.Message != e.Message || got.Sequence != e.Sequence {
+			t.Errorf("Pattern mismatch,\n\texpected: %+v\n\tgot: %+v", e, got)
+		}
+		if !reflect.DeepEqual(got.Fields, e.Fields) {
+			t.Errorf("Pattern mismatch,\n\texpected: %+v\n\tgot: %+v", e.Fields, got.Fields)
+		}
+	}
+	if _, err := r.ReadEntry(); err != io.EOF {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", io.EOF, err)
+	}
+}
+
+func TestProtoReaderInvalid(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewProtoWriter(buf)
+	_ = w.WriteEntry(&Entry{Level: LevelWarn, Message: "Truncated"})
+	truncated := buf.Bytes()[:buf.Len()-2]
+	if _, err := NewProtoReader(bytes.NewReader(truncated)).ReadEntry(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", io.ErrUnexpectedEOF, err)
+	}
+	// A length-delimited field longer than its record.
+	if _, err := NewProtoReader(bytes.NewReader([]byte{2, 0x1a, 5})).ReadEntry(); err != ErrInvalidProto {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", ErrInvalidProto, err)
+	}
+}
@@ -0,0 +1,42 @@
+package logger
+
+import "time"
+
+// quietWindow is a period set by AddQuietWindow, levels is nil if all levels are silenced.
+type quietWindow struct {
+	start, end time.Time
+	levels     []Level
+}
+
+func (l *logger) AddQuietWindow(start, end time.Time, levels ...Level) {
+	if !end.After(start) {
+		return
+	}
+	l.Lock()
+	defer l.Unlock()
+	l.quiet = append(l.quiet, quietWindow{start: start, end: end, levels: append([]Level(nil), levels...)})
+}
+
+// isQuiet tells whether entries of level are silenced at t. It must be called with the lock held.
+func (l *logger) isQuiet(t time.Time, level Level) bool {
+	for i := range l.quiet {
+		w := &l.quiet[i]
+		if !t.Before(w.start) && t.Before(w.end) && w.matches(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches tells whether the window silences entries of level.
+func (w *quietWindow) matches(level Level) bool {
+	if len(w.levels) == 0 {
+		return true
+	}
+	for _, lv := range w.levels {
+		if lv == level {
+			return true
+		}
+	}
+	return false
+}
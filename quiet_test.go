@@ -0,0 +1,40 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestQuietWindow(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	l.SetTimeFunc(func() time.Time { return now })
+	l.AddQuietWindow(now.Add(time.Minute), now.Add(2*time.Minute), LevelInfo, LevelDebug)
+	l.AddQuietWindow(now.Add(time.Hour), now.Add(2*time.Hour))
+
+	tests := []struct {
+		at       time.Duration
+		level    Level
+		expected string
+	}{
+		{0, LevelInfo, "I/10:00:00 : Entry\n"},
+		{time.Minute, LevelInfo, ""},
+		{90 * time.Second, LevelDebug, ""},
+		{90 * time.Second, LevelError, "E/10:01:30 : Entry\n"},
+		{2 * time.Minute, LevelInfo, "I/10:02:00 : Entry\n"},
+		{time.Hour, LevelError, ""},
+		{2 * time.Hour, LevelError, "E/12:00:00 : Entry\n"},
+	}
+	for _, test := range tests {
+		buf.Reset()
+		now = time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local).Add(test.at)
+		l.Println(test.level, "Entry")
+		if got := buf.String(); got != test.expected {
+			t.Errorf("Pattern mismatch at +%s,\n\texpected: %q\n\tgot: %q", test.at, test.expected, got)
+		}
+	}
+}
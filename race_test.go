@@ -0,0 +1,120 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentConfig logs from many goroutines while others keep changing the configuration.
+// Run it with go test -race to detect unsynchronized accesses.
+func TestConcurrentConfig(t *testing.T) {
+	l := New(LevelTrace, "RACE", io.Discard, 0)
+	var writers, mutating sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		writers.Add(1)
+		go func(i int) {
+			defer writers.Done()
+			for j := 0; j < 200; j++ {
+				l.Println(LevelInfo, "Writer", i, j)
+				l.Printf(LevelWarn, "Writer %d %d", i, j)
+				l.PrintFields(LevelError, "Writer", Int("i", i), Int("j", j))
+				_ = l.Clone()
+			}
+		}(i)
+	}
+	mutators := []func(){
+		func() { l.SetFlags(FlagColorMode | FlagNumericLevel) },
+		func() { l.SetFlags(FlagIndentMultiline) },
+		func() { l.SetPrefix("MUTATED") },
+		func() { l.SetLevel(LevelDebug) },
+		func() { l.SetLevel(LevelTrace) },
+		func() { l.SetLevelLabel(LevelError, "[ERROR] ") },
+		func() { l.SetLevelLabel(LevelError, "") },
+		func() { l.SetBodySeparator("\t") },
+		func() { l.SetMaxLineLength(40) },
+		func() { l.SetFieldOrder(FieldOrderInsertion) },
+		func() { l.SetOutput(io.Discard) },
+		func() { l.SetFatalExits(false) },
+		func() { _, _, _, _ = l.GetFlags(), l.GetPrefix(), l.GetLevel(), l.GetOutput() },
+	}
+	for _, mutate := range mutators {
+		mutating.Add(1)
+		go func(mutate func()) {
+			defer mutating.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					mutate()
+				}
+			}
+		}(mutate)
+	}
+	// Writers finish on their own, then mutators are stopped.
+	writers.Wait()
+	close(stop)
+	mutating.Wait()
+}
+
+// TestConcurrentSetOutput swaps the output while many goroutines log multiline entries,
+// then checks that every entry landed wholly in one of the writers.
+func TestConcurrentSetOutput(t *testing.T) {
+	outputs := make([]*bytes.Buffer, 4)
+	for i := range outputs {
+		outputs[i] = new(bytes.Buffer)
+	}
+	l := New(LevelTrace, "", outputs[0], FlagIndentMultiline)
+	var writers sync.WaitGroup
+	stop, swapped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(swapped)
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				l.SetOutput(outputs[i%len(outputs)])
+			}
+		}
+	}()
+	const goroutines, entries = 8, 500
+	for i := 0; i < goroutines; i++ {
+		writers.Add(1)
+		go func(i int) {
+			defer writers.Done()
+			for j := 0; j < entries; j++ {
+				l.Printf(LevelInfo, "begin %d %d\nmiddle\nend", i, j)
+			}
+		}(i)
+	}
+	writers.Wait()
+	close(stop)
+	<-swapped
+
+	total := 0
+	for _, out := range outputs {
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if len(lines) == 1 && lines[0] == "" {
+			continue
+		}
+		if len(lines)%3 != 0 {
+			t.Fatalf("Entry split across writers, got %d lines", len(lines))
+		}
+		for k := 0; k < len(lines); k += 3 {
+			if !strings.Contains(lines[k], " : begin ") || lines[k+1] != "I| middle" || lines[k+2] != "I| end" {
+				t.Fatalf("Entry split across writers,\n\tgot: %q", lines[k:k+3])
+			}
+		}
+		total += len(lines) / 3
+	}
+	if total != goroutines*entries {
+		t.Errorf("Pattern mismatch,\n\texpected: %d entries\n\tgot: %d entries", goroutines*entries, total)
+	}
+}
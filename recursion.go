@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// recursionWarning is written once per logger when a hook, the error handler or the output logs back into it.
+const recursionWarning = "recursive logging detected, nested entries are dropped"
+
+// goroutineID returns the id of the calling goroutine, parsed from the "goroutine 42 [running]:" stack header.
+func goroutineID() uint64 {
+	var b [64]byte
+	s := b[:runtime.Stack(b[:], false)]
+	s = bytes.TrimPrefix(s, []byte("goroutine "))
+	if i := bytes.IndexByte(s, ' '); i > 0 {
+		s = s[:i]
+	}
+	id, _ := strconv.ParseUint(string(s), 10, 64)
+	return id
+}
+
+// runCallback calls fn, which is a hook or the error handler, marking the calling goroutine so that
+// entries it logs back into the logger are detected and dropped instead of recursing endlessly.
+func (l *logger) runCallback(fn func()) {
+	id := goroutineID()
+	l.Lock()
+	if l.callbacks == nil {
+		l.callbacks = make(map[uint64]int)
+	}
+	l.callbacks[id]++
+	l.Unlock()
+	defer func() {
+		l.Lock()
+		if l.callbacks[id]--; l.callbacks[id] == 0 {
+			delete(l.callbacks, id)
+		}
+		l.Unlock()
+	}()
+	fn()
+}
+
+// recursive tells whether the calling goroutine is running a callback of the logger. If so, the warning
+// is written unless it was already. It must be called with the lock held.
+func (l *logger) recursive() bool {
+	if len(l.callbacks) == 0 {
+		return false
+	}
+	if _, ok := l.callbacks[goroutineID()]; !ok {
+		return false
+	}
+	l.warnRecursion()
+	return true
+}
+
+// warnRecursion writes the recursion warning, once and only if LevelWarn is enabled.
+// It must be called with the lock held.
+func (l *logger) warnRecursion() {
+	if !l.recursionWarned && atomic.LoadInt32(&l.level) >= int32(LevelWarn) {
+		l.recursionWarned = true
+		_, _, _ = l.format(nil, l.now(), LevelWarn, nil, func(b *buffer) { *b = append(*b, recursionWarning...) })
+	}
+}
+
+// plainOutput tells whether out is known not to log, so that writing to it needs no guard against recursion,
+// which costs a stack trace per entry.
+func plainOutput(out io.Writer) bool {
+	switch out.(type) {
+	case *os.File, *bytes.Buffer, *RotatingFileWriter, *ReopenableFileWriter:
+		return true
+	}
+	return out == io.Discard
+}
+
+// guardWrite marks the calling goroutine as writing to the output until the returned function is called,
+// which writes the recursion warning if the output logged back meanwhile. It must be called with the lock held.
+func (l *logger) guardWrite() func() {
+	atomic.StoreUint64(&l.writer, goroutineID())
+	return func() {
+		atomic.StoreUint64(&l.writer, 0)
+		if l.writeRecursed {
+			l.writeRecursed = false
+			l.warnRecursion()
+		}
+	}
+}
+
+// recursiveWrite tells whether the calling goroutine is writing to the output, i.e. the output logs back into
+// the logger. The lock is then held by this very goroutine, so the entry must be dropped without waiting for it.
+func (l *logger) recursiveWrite() bool {
+	if w := atomic.LoadUint64(&l.writer); w == 0 || w != goroutineID() {
+		return false
+	}
+	l.writeRecursed = true
+	return true
+}
@@ -0,0 +1,136 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hookFunc adapts a function to the Hook interface.
+type hookFunc func(e *Entry)
+
+func (f hookFunc) Fire(e *Entry) { f(e) }
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestRecursiveLogging(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	fired := 0
+	l.AddHook(hookFunc(func(e *Entry) {
+		fired++
+		l.Println(LevelInfo, "Logged from hook:", e.Message)
+	}))
+	l.Println(LevelInfo, "first")
+	l.Println(LevelInfo, "second")
+	out := buf.String()
+	t.Log("Got: ", out)
+	if fired != 2 {
+		t.Errorf("Pattern mismatch,\n\texpected: 2 fires\n\tgot: %d fires", fired)
+	}
+	if strings.Contains(out, "Logged from hook") {
+		t.Errorf("Nested entry was written")
+	}
+	if n := strings.Count(out, recursionWarning); n != 1 {
+		t.Errorf("Pattern mismatch,\n\texpected: 1 warning\n\tgot: %d warnings", n)
+	}
+	if !strings.Contains(out, "I/") || !strings.Contains(out, "W/") || !strings.Contains(out, "second") {
+		t.Errorf("Top-level entries are missing")
+	}
+
+	// An error handler logging into its failing logger does not recurse either.
+	l = New(LevelTrace, "", failingWriter{}, 0)
+	handled := 0
+	l.SetErrorHandler(func(err error) {
+		handled++
+		l.Println(LevelError, "Write failed:", err)
+	})
+	l.Println(LevelInfo, "lost")
+	if handled != 1 {
+		t.Errorf("Pattern mismatch,\n\texpected: 1 handled error\n\tgot: %d handled errors", handled)
+	}
+}
+
+// TestConcurrentHooks checks that entries of other goroutines are not mistaken for nested ones while a hook runs.
+func TestConcurrentHooks(t *testing.T) {
+	out := &lockedBuffer{}
+	l := New(LevelTrace, "", out, 0)
+	inHook, release := make(chan struct{}), make(chan struct{})
+	l.AddHook(hookFunc(func(e *Entry) {
+		if e.Message == "blocked in hook" {
+			close(inHook)
+			<-release
+		}
+	}))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Println(LevelInfo, "blocked in hook")
+	}()
+	<-inHook
+	l.Println(LevelInfo, "other goroutine")
+	close(release)
+	<-done
+	if got := out.String(); !strings.Contains(got, "other goroutine") || strings.Contains(got, recursionWarning) {
+		t.Errorf("Entry of another goroutine was dropped: %s", got)
+	}
+}
+
+// loggingWriter is an output which logs back into its logger, e.g. to report its own failures.
+type loggingWriter struct {
+	l   ILogger
+	buf bytes.Buffer
+}
+
+func (w *loggingWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	w.l.Println(LevelError, "Logged from writer")
+	return len(p), nil
+}
+
+func TestRecursiveWrite(t *testing.T) {
+	w := &loggingWriter{}
+	l := New(LevelTrace, "", w, 0)
+	w.l = l
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Println(LevelInfo, "first")
+		l.Println(LevelInfo, "second")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Logging from the output deadlocked")
+	}
+	out := w.buf.String()
+	t.Log("Got: ", out)
+	if strings.Contains(out, "Logged from writer") {
+		t.Errorf("Nested entry was written")
+	}
+	if n := strings.Count(out, recursionWarning); n != 1 {
+		t.Errorf("Pattern mismatch,\n\texpected: 1 warning\n\tgot: %d warnings", n)
+	}
+	if !strings.Contains(out, "first") || !strings.Contains(out, "second") {
+		t.Errorf("Top-level entries are missing")
+	}
+}
+
+func TestRecursionWarningLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelError, "", buf, 0)
+	l.AddHook(hookFunc(func(e *Entry) {
+		l.Println(LevelError, "Logged from hook")
+	}))
+	l.Println(LevelError, "Top-level")
+	if got, expected := buf.String(), "Top-level\n"; !strings.HasSuffix(got, expected) || strings.Count(got, "\n") != 1 {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+}
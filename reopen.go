@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"os"
+	"sync"
+)
+
+// ReopenableFileWriter is an io.Writer appending to a file which can be reopened by its path.
+// It is compatible with tools like logrotate, which rename the file and ask the process to reopen it.
+type ReopenableFileWriter struct {
+	path string
+	file *os.File
+	// header is written first to empty files, empty tells whether the open file has no content yet.
+	header []byte
+	empty  bool
+	sync.Mutex
+}
+
+// NewReopenableFileWriter opens (or creates) the file at path in append mode.
+func NewReopenableFileWriter(path string) (*ReopenableFileWriter, error) {
+	w := &ReopenableFileWriter{path: path}
+	if err := w.Reopen(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends p to the currently open file.
+func (w *ReopenableFileWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.empty && len(w.header) > 0 {
+		if _, err := w.file.Write(w.header); err != nil {
+			return 0, err
+		}
+	}
+	w.empty = false
+	return w.file.Write(p)
+}
+
+// SetHeader sets a header, like a schema line, written at the start of every new file before the first entry.
+// Files which already have content when opened do not get it. A nil header disables it.
+func (w *ReopenableFileWriter) SetHeader(header []byte) {
+	w.Lock()
+	defer w.Unlock()
+	w.header = append([]byte(nil), header...)
+}
+
+// Reopen closes the current file and opens the original path again, creating it if it was moved away.
+// On failure the previous file is kept, so writes are not lost.
+func (w *ReopenableFileWriter) Reopen() error {
+	f, err := openLogFile(w.path)
+	if err != nil {
+		return err
+	}
+	empty := isEmptyFile(f)
+	w.Lock()
+	defer w.Unlock()
+	if w.file != nil {
+		_ = w.file.Close()
+	}
+	w.file, w.empty = f, empty
+	return nil
+}
+
+// Close closes the current file. Subsequent writes fail until Reopen is called.
+func (w *ReopenableFileWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
@@ -0,0 +1,92 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReopenableFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := NewReopenableFileWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	l := New(LevelTrace, "", w, 0)
+	l.Println(LevelInfo, "Before rotation")
+
+	// Simulate logrotate: move the file away, then reopen.
+	rotated := path + ".1"
+	if err = os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	l.Println(LevelInfo, "After rotation")
+
+	for file, expected := range map[string]string{rotated: "Before rotation\n", path: "After rotation\n"} {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := string(b)
+		t.Log("Got: ", out)
+		if len(out) < 13 || out[13:] != expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %s\n\tgot: %s", expected, out)
+		}
+	}
+
+	stop := ReopenOnSIGHUP(w)
+	stop()
+	stop()
+}
+
+func TestReopenableFileHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewReopenableFileWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetHeader([]byte("# time level message\n"))
+	l := New(LevelTrace, "", w, 0)
+	l.Println(LevelInfo, "First")
+	l.Println(LevelInfo, "Second")
+
+	// Reopening the same file does not repeat the header, a new file gets it.
+	if err = w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	l.Println(LevelInfo, "Third")
+	if err = os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	l.Println(LevelInfo, "Fourth")
+
+	for file, expected := range map[string][]string{
+		path + ".1": {"# time level message", "First", "Second", "Third"},
+		path:        {"# time level message", "Fourth"},
+	} {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+		ok := len(lines) == len(expected) && lines[0] == expected[0]
+		for i := 1; ok && i < len(lines); i++ {
+			ok = len(lines[i]) > 13 && lines[i][13:] == expected[i]
+		}
+		if !ok {
+			t.Errorf("Pattern mismatch in %s,\n\texpected: %q\n\tgot: %q", file, expected, lines)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RotatingFileWriter is an io.Writer appending to a file which is rotated once it would exceed a maximum size:
+// the file is renamed with a ".1" suffix, replacing the previous backup, and a new file is started.
+// A single write is never split, so a file may exceed the size if one write is larger than it.
+type RotatingFileWriter struct {
+	path    string
+	maxSize int64
+	size    int64
+	file    *os.File
+	header  []byte
+	sync.Mutex
+}
+
+// NewRotatingFileWriter opens (or creates) the file at path in append mode, rotating it at maxSize bytes.
+func NewRotatingFileWriter(path string, maxSize int64) (*RotatingFileWriter, error) {
+	f, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &RotatingFileWriter{path: path, maxSize: maxSize, size: info.Size(), file: f}, nil
+}
+
+// Write appends p to the file, rotating it first if p does not fit.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	if w.size == 0 && len(w.header) > 0 {
+		n, err := w.file.Write(w.header)
+		w.size += int64(n)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// SetHeader sets a header, like a schema line, written at the start of every new file before the first entry,
+// including after each rotation. Its size counts toward the maximum size. A nil header disables it.
+func (w *RotatingFileWriter) SetHeader(header []byte) {
+	w.Lock()
+	defer w.Unlock()
+	w.header = append([]byte(nil), header...)
+}
+
+// rotate renames the file to its backup name and starts a new one. It must be called with the lock held.
+func (w *RotatingFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	if err := os.Rename(w.path, w.path+".1"); err != nil {
+		// Keep appending to the original file, rather than failing every later write.
+		if f, e := openLogFile(w.path); e == nil {
+			w.file = f
+		}
+		return err
+	}
+	f, err := openLogFile(w.path)
+	if err != nil {
+		return err
+	}
+	w.file, w.size = f, 0
+	return nil
+}
+
+// Close closes the file, subsequent writes fail with os.ErrClosed.
+func (w *RotatingFileWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// leveledFiles maps levels to the file they are written to by SetLeveledFileOutputs.
+var leveledFiles = map[Level]string{
+	LevelFatal: "error.log",
+	LevelError: "error.log",
+	LevelWarn:  "warn.log",
+	LevelInfo:  "info.log",
+	LevelDebug: "debug.log",
+	LevelTrace: "trace.log",
+}
+
+func (l *logger) SetLeveledFileOutputs(dir string, maxSize int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	writers := make(map[string]*RotatingFileWriter)
+	for _, name := range leveledFiles {
+		if writers[name] != nil {
+			continue
+		}
+		w, err := NewRotatingFileWriter(filepath.Join(dir, name), int64(maxSize))
+		if err != nil {
+			for _, w := range writers {
+				_ = w.Close()
+			}
+			return err
+		}
+		writers[name] = w
+	}
+	for level, name := range leveledFiles {
+		l.SetLevelOutput(level, writers[name])
+	}
+	// The logger owns the files: Close closes them, and the ones of a previous call are closed here.
+	closers := make([]io.Closer, 0, len(writers))
+	for _, w := range writers {
+		closers = append(closers, w)
+	}
+	l.Lock()
+	prev := l.levelClosers
+	l.levelClosers = closers
+	l.Unlock()
+	for _, c := range prev {
+		_ = c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestLeveledFileOutputs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	l := New(LevelTrace, "", nil, 0)
+	// Every entry is 27 bytes long, so the files rotate on the third one.
+	if err := l.SetLeveledFileOutputs(dir, 60); err != nil {
+		t.Fatal(err)
+	}
+	l.SetFatalExits(false)
+	l.Println(LevelError, "error entry 1")
+	l.Println(LevelFatal, "fatal entry 1")
+	l.Println(LevelError, "error entry 2")
+	l.Println(LevelWarn, "warn entry 01")
+	l.Println(LevelInfo, "info entry 01")
+	l.Println(LevelInfo, "info entry 02")
+	l.Println(LevelDebug, "debug entry 1")
+
+	tests := []struct {
+		file, expected string
+	}{
+		{"error.log", "error entry 2\n"},
+		{"error.log.1", "error entry 1\nfatal entry 1\n"},
+		{"warn.log", "warn entry 01\n"},
+		{"warn.log.1", ""},
+		{"info.log", "info entry 01\ninfo entry 02\n"},
+		{"debug.log", "debug entry 1\n"},
+		{"trace.log", ""},
+	}
+	for _, test := range tests {
+		var got string
+		for _, line := range strings.SplitAfter(readFile(t, filepath.Join(dir, test.file)), "\n") {
+			if len(line) > 13 {
+				got += line[13:]
+			}
+		}
+		if got != test.expected {
+			t.Errorf("Pattern mismatch in %s,\n\texpected: %q\n\tgot: %q", test.file, test.expected, got)
+		}
+	}
+
+	// Removing a route falls back to the main output, which discards here.
+	l.SetLevelOutput(LevelDebug, nil)
+	l.Println(LevelDebug, "debug entry 2")
+	if got := readFile(t, filepath.Join(dir, "debug.log")); strings.Contains(got, "entry 2") {
+		t.Errorf("Entry was written to a removed route: %s", got)
+	}
+}
+
+func TestRotatingFileHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingFileWriter(path, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetHeader([]byte("[\n"))
+	l := New(LevelTrace, "", w, 0)
+	// With the header, two 28 bytes entries fit in a file.
+	for i := 1; i <= 3; i++ {
+		l.Printf(LevelInfo, "entry number %d", i)
+	}
+	for file, expected := range map[string]string{path + ".1": "[\nentry number 1\nentry number 2\n", path: "[\nentry number 3\n"} {
+		var got string
+		for _, line := range strings.SplitAfter(readFile(t, file), "\n") {
+			if len(line) > 13 {
+				line = line[13:]
+			}
+			got += line
+		}
+		if got != expected {
+			t.Errorf("Pattern mismatch in %s,\n\texpected: %q\n\tgot: %q", file, expected, got)
+		}
+	}
+}
+
+func TestLeveledFileOutputsClose(t *testing.T) {
+	dir := t.TempDir()
+	l := New(LevelTrace, "", nil, 0)
+	var writers []io.Writer
+	for i := 0; i < 2; i++ {
+		if err := l.SetLeveledFileOutputs(dir, 1024); err != nil {
+			t.Fatal(err)
+		}
+		writers = append(writers, l.(*logger).levelOut[LevelInfo])
+	}
+	// The files of the first call were closed when they were replaced, the second ones by Close.
+	if _, err := writers[0].Write([]byte("Leaked\n")); err != os.ErrClosed {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", os.ErrClosed, err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writers[1].Write([]byte("Leaked\n")); err != os.ErrClosed {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", os.ErrClosed, err)
+	}
+}
+
+func TestRotateRenameFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotatingFileWriter(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	// The backup name is taken by a directory, so renaming fails.
+	if err := os.Mkdir(path+".1", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("first entry\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("second entry\n")); err == nil {
+		t.Error("Rotation did not fail")
+	}
+	if err := os.Remove(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("third entry\n")); err != nil {
+		t.Errorf("Writer did not recover from the failed rotation: %v", err)
+	}
+	if got := readFile(t, path) + readFile(t, path+".1"); !strings.Contains(got, "first entry\n") || !strings.Contains(got, "third entry\n") {
+		t.Errorf("Pattern mismatch,\n\texpected: first and third entries\n\tgot: %q", got)
+	}
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// ShardedFileWriter is an io.Writer distributing writes round-robin across a number of files, so that they can
+// be ingested in parallel. Every shard is a RotatingFileWriter rotated independently. Writes are never split,
+// so each entry ends up whole in one shard, but entries of different shards are not ordered.
+type ShardedFileWriter struct {
+	shards []*RotatingFileWriter
+	next   int
+	sync.Mutex
+}
+
+// NewShardedFileWriter opens (or creates) shards files in dir, creating it if needed: shard-0.log, shard-1.log
+// and so on. Each of them is rotated at maxSize bytes.
+func NewShardedFileWriter(dir string, shards int, maxSize int) (*ShardedFileWriter, error) {
+	if shards < 1 {
+		return nil, errors.New("logger: invalid number of shards " + strconv.Itoa(shards))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	w := &ShardedFileWriter{shards: make([]*RotatingFileWriter, 0, shards)}
+	for i := 0; i < shards; i++ {
+		shard, err := NewRotatingFileWriter(filepath.Join(dir, "shard-"+strconv.Itoa(i)+".log"), int64(maxSize))
+		if err != nil {
+			_ = w.Close()
+			return nil, err
+		}
+		w.shards = append(w.shards, shard)
+	}
+	return w, nil
+}
+
+// Write appends p to the next shard.
+func (w *ShardedFileWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	shard := w.shards[w.next]
+	w.next = (w.next + 1) % len(w.shards)
+	w.Unlock()
+	return shard.Write(p)
+}
+
+// Close closes every shard and returns the first error, subsequent writes fail with os.ErrClosed.
+func (w *ShardedFileWriter) Close() error {
+	var first error
+	for _, shard := range w.shards {
+		if err := shard.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,43 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestShardedFileWriter(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shards")
+	// Every entry is 21 bytes long, so each shard rotates on its third entry.
+	w, err := NewShardedFileWriter(dir, 3, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	l := New(LevelTrace, "", w, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	for i := 0; i < 7; i++ {
+		l.Printf(LevelInfo, "entry %d", i)
+	}
+
+	tests := []struct {
+		file, expected string
+	}{
+		{"shard-0.log", "I/10:00:00 : entry 6\n"},
+		{"shard-0.log.1", "I/10:00:00 : entry 0\nI/10:00:00 : entry 3\n"},
+		{"shard-1.log", "I/10:00:00 : entry 1\nI/10:00:00 : entry 4\n"},
+		{"shard-1.log.1", ""},
+		{"shard-2.log", "I/10:00:00 : entry 2\nI/10:00:00 : entry 5\n"},
+	}
+	for _, test := range tests {
+		if got := readFile(t, filepath.Join(dir, test.file)); got != test.expected {
+			t.Errorf("Pattern mismatch in %s,\n\texpected: %q\n\tgot: %q", test.file, test.expected, got)
+		}
+	}
+
+	if _, err := NewShardedFileWriter(dir, 0, 50); err == nil {
+		t.Error("Zero shards were accepted")
+	}
+}
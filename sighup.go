@@ -0,0 +1,36 @@
+//go:build !js && !wasip1 && !plan9
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// ReopenOnSIGHUP reopens w every time the process receives SIGHUP, as logrotate expects.
+// Errors from Reopen are ignored and the previous file stays in use.
+// Call the returned function to stop handling the signal.
+func ReopenOnSIGHUP(w *ReopenableFileWriter) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = w.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+//go:build js || wasip1 || plan9
+
+package logger
+
+// ReopenOnSIGHUP does nothing, SIGHUP does not exist on this platform.
+func ReopenOnSIGHUP(w *ReopenableFileWriter) (stop func()) {
+	return func() {}
+}
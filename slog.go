@@ -0,0 +1,85 @@
+//go:build go1.21
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+)
+
+// These slog levels are used for records created from every Level.
+var slogLevels = map[Level]slog.Level{
+	LevelFatal: slog.LevelError + 4,
+	LevelError: slog.LevelError,
+	LevelWarn:  slog.LevelWarn,
+	LevelInfo:  slog.LevelInfo,
+	LevelDebug: slog.LevelDebug,
+	LevelTrace: slog.LevelDebug - 4,
+}
+
+// SlogWriter forwards log entries to a slog.Handler, so that an existing slog backend can be kept.
+// Use it as output, e.g. l.SetOutput(NewSlogWriter(h)). Every entry becomes a slog.Record
+// with the entry time, the mapped level, the message and one attribute per Field.
+// It requires GO v1.21+.
+type SlogWriter struct {
+	handler slog.Handler
+}
+
+// slogOutput holds the methods of ILogger requiring GO v1.21+.
+type slogOutput interface {
+	// SetSlogHandler sets the output to a SlogWriter dispatching entries to h, see NewSlogWriter.
+	// Passing nil sets no output, so nothing is written. It requires GO v1.21+.
+	SetSlogHandler(h slog.Handler)
+}
+
+func (l *logger) SetSlogHandler(h slog.Handler) {
+	if h == nil {
+		l.SetOutput(nil)
+		return
+	}
+	l.SetOutput(NewSlogWriter(h))
+}
+
+// NewSlogWriter returns a SlogWriter dispatching records to h.
+func NewSlogWriter(h slog.Handler) *SlogWriter {
+	return &SlogWriter{handler: h}
+}
+
+// Write dispatches p as the message of a record at slog.LevelInfo.
+// It is used when the writer is wrapped by another io.Writer, the logger itself calls WriteEntry.
+func (w *SlogWriter) Write(p []byte) (int, error) {
+	err := w.WriteEntry(&Entry{Level: LevelInfo, Message: string(bytes.TrimSuffix(p, []byte{'\n'}))})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteEntry dispatches the Entry to the handler as a slog.Record, if the handler is enabled for its level.
+func (w *SlogWriter) WriteEntry(e *Entry) error {
+	ctx := context.Background()
+	level := slogLevels[e.Level]
+	if !w.handler.Enabled(ctx, level) {
+		return nil
+	}
+	r := slog.NewRecord(e.Time, level, e.Message, 0)
+	for _, f := range e.Fields {
+		r.AddAttrs(slogAttr(f))
+	}
+	return w.handler.Handle(ctx, r)
+}
+
+func slogAttr(f Field) slog.Attr {
+	switch f.kind {
+	case kindInt:
+		return slog.Int64(f.Key, f.num)
+	case kindBool:
+		return slog.Bool(f.Key, f.num != 0)
+	case kindError:
+		return slog.Any(f.Key, f.err)
+	case kindAny:
+		return slog.Any(f.Key, f.any)
+	}
+	return slog.String(f.Key, f.str)
+}
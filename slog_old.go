@@ -0,0 +1,6 @@
+//go:build !go1.21
+
+package logger
+
+// slogOutput is empty, log/slog requires GO v1.21+.
+type slogOutput interface{}
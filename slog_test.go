@@ -0,0 +1,71 @@
+//go:build go1.21 && !nolog
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+// captureHandler records every slog.Record it handles.
+type captureHandler struct {
+	records []slog.Record
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *captureHandler) WithGroup(string) slog.Handler { return h }
+
+func TestSlogWriter(t *testing.T) {
+	h := &captureHandler{}
+	l := New(LevelTrace, "", NewSlogWriter(h), 0)
+	l.PrintFields(LevelWarn, "Slow request", String("path", "/api"), Int("ms", 1500), Err(errors.New("timeout")))
+	l.Println(LevelTrace, "Traced")
+	if len(h.records) != 2 {
+		t.Fatalf("Pattern mismatch,\n\texpected: 2 records\n\tgot: %d records", len(h.records))
+	}
+
+	r := h.records[0]
+	if r.Level != slog.LevelWarn || r.Message != "Slow request" {
+		t.Errorf("Pattern mismatch,\n\texpected: %v %s\n\tgot: %v %s", slog.LevelWarn, "Slow request", r.Level, r.Message)
+	}
+	attrs := map[string]string{}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+	for k, v := range map[string]string{"path": "/api", "ms": "1500", "error": "timeout"} {
+		if attrs[k] != v {
+			t.Errorf("Pattern mismatch for %s,\n\texpected: %s\n\tgot: %s", k, v, attrs[k])
+		}
+	}
+
+	r = h.records[1]
+	if r.Level != slog.LevelDebug-4 || r.Message != "Traced" {
+		t.Errorf("Pattern mismatch,\n\texpected: %v %s\n\tgot: %v %s", slog.LevelDebug-4, "Traced", r.Level, r.Message)
+	}
+}
+
+func TestSetSlogHandler(t *testing.T) {
+	h := &captureHandler{}
+	l := New(LevelTrace, "", nil, 0)
+	l.SetSlogHandler(h)
+	l.PrintFields(LevelError, "Routed", Int("code", 7))
+	if len(h.records) != 1 || h.records[0].Level != slog.LevelError || h.records[0].Message != "Routed" {
+		t.Fatalf("Pattern mismatch,\n\texpected: a Routed record at %v\n\tgot: %v", slog.LevelError, h.records)
+	}
+	l.SetSlogHandler(nil)
+	l.Println(LevelError, "Not routed")
+	if len(h.records) != 1 || l.GetOutput() != nil {
+		t.Errorf("Handler was not removed: %v", h.records)
+	}
+}
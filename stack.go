@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// panicSite returns the file:line where the panic being recovered was raised, or an empty string if unknown.
+// It must be called from a function deferred directly, like RecoverAndLog.
+func panicSite() string {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	panicking := false
+	for {
+		f, more := frames.Next()
+		if panicking && !strings.HasPrefix(f.Function, "runtime.") {
+			return f.File + ":" + strconv.Itoa(f.Line)
+		}
+		if f.Function == "runtime.gopanic" {
+			panicking = true
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// pkgDir is the directory of the source files of this package, used to skip its frames.
+var pkgDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// callerPackage returns the import path of the package of the first caller outside this package, e.g.
+// github.com/acme/app/db, or an empty string if unknown. Test files of this package count as callers.
+func callerPackage() string {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		f, more := frames.Next()
+		if filepath.Dir(f.File) != pkgDir || strings.HasSuffix(f.File, "_test.go") {
+			return funcPackage(f.Function)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// funcPackage returns the import path part of a function name like github.com/acme/app/db.(*Conn).Query.
+// Dots in the last element of the path are escaped as %2e in function names, e.g. gopkg.in/yaml%2ev3.Unmarshal.
+func funcPackage(name string) string {
+	slash := strings.LastIndexByte(name, '/') + 1
+	if dot := strings.IndexByte(name[slash:], '.'); dot >= 0 {
+		name = name[:slash+dot]
+	}
+	return strings.ReplaceAll(name, "%2e", ".")
+}
+
+func (l *logger) SetStackDedupWindow(window time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	l.stackWindow = window
+	l.stackSeen = nil
+}
+
+// stackLogged tells whether the stack trace of a panic raised at site was logged within the dedup window,
+// recording it otherwise. It returns the time it was first logged.
+func (l *logger) stackLogged(site string) (time.Time, bool) {
+	l.Lock()
+	defer l.Unlock()
+	if l.stackWindow <= 0 || site == "" {
+		return time.Time{}, false
+	}
+	now := l.now()
+	if first, ok := l.stackSeen[site]; ok && now.Sub(first) < l.stackWindow {
+		return first, true
+	}
+	if l.stackSeen == nil {
+		l.stackSeen = make(map[string]time.Time)
+	}
+	for s, first := range l.stackSeen {
+		if now.Sub(first) >= l.stackWindow {
+			delete(l.stackSeen, s)
+		}
+	}
+	l.stackSeen[site] = now
+	return now, false
+}
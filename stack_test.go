@@ -0,0 +1,85 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStackDedupWindow(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	l.SetTimeFunc(func() time.Time { return now })
+	l.SetStackDedupWindow(time.Minute)
+	fail := func(site int) {
+		defer l.RecoverAndLog(LevelError)
+		if site == 0 {
+			panic("first site")
+		}
+		panic("second site")
+	}
+	tests := []struct {
+		site      int
+		advance   time.Duration
+		fullStack bool
+	}{
+		{0, 0, true},
+		{0, time.Second, false},
+		{1, 0, true},
+		{0, time.Minute, true},
+	}
+	for i, test := range tests {
+		buf.Reset()
+		now = now.Add(test.advance)
+		fail(test.site)
+		out := buf.String()
+		t.Log("Got: ", out)
+		if full := strings.Contains(out, "TestStackDedupWindow"); full != test.fullStack {
+			t.Errorf("Pattern mismatch for panic %d,\n\texpected: full stack %v\n\tgot: %s", i, test.fullStack, out)
+		}
+		if !test.fullStack && !strings.HasSuffix(out, "(stack trace omitted, logged at 10:00:00)\n") {
+			t.Errorf("Pattern mismatch for panic %d,\n\texpected: %s\n\tgot: %s", i, "a reference to 10:00:00", out)
+		}
+	}
+}
+
+func TestFlagPackage(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, FlagPackage)
+	l.Println(LevelInfo, "Entry")
+	l.PrintFields(LevelInfo, "Fields", String("a", "1"))
+	tx := l.Begin()
+	tx.Println(LevelInfo, "Committed")
+	_ = tx.Commit()
+	out := buf.String()
+	t.Log("Got: ", out)
+	for _, expected := range []string{
+		"Entry package=github.com/chitholian/GoLogger\n",
+		"Fields a=1 package=github.com/chitholian/GoLogger\n",
+		"Committed package=github.com/chitholian/GoLogger\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, out)
+		}
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	tests := []struct {
+		name, expected string
+	}{
+		{"github.com/acme/app/db.(*Conn).Query", "github.com/acme/app/db"},
+		{"github.com/acme/app/db.Open.func1", "github.com/acme/app/db"},
+		{"main.main", "main"},
+		{"gopkg.in/yaml%2ev3.Unmarshal", "gopkg.in/yaml.v3"},
+	}
+	for _, test := range tests {
+		if got := funcPackage(test.name); got != test.expected {
+			t.Errorf("Pattern mismatch for %s,\n\texpected: %s\n\tgot: %s", test.name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Tx gathers log entries during an operation, to be written only if needed, e.g. when the operation fails.
+// See ILogger.Begin. It is safe for concurrent use.
+type Tx interface {
+	// Print, Println and Printf are like the methods of ILogger, but the entry is kept until Commit.
+	// The level is checked and the time is taken when they are called. A LevelFatal entry commits the
+	// transaction, then exits like ILogger.Print does.
+	Print(level Level, v ...any)
+	Println(level Level, v ...any)
+	Printf(level Level, format string, v ...any)
+	// PrintFields is like ILogger.PrintFields, but the entry is kept until Commit.
+	PrintFields(level Level, msg string, fields ...Field)
+
+	// Commit writes the kept entries to the logger in order, with their original time, and returns the first
+	// error. The errors are passed to the error handler too, like for entries written directly.
+	// The transaction can be used again afterwards.
+	Commit() error
+	// Discard drops the kept entries. The transaction can be used again afterwards.
+	Discard()
+}
+
+// txEntry is an entry kept by a Tx.
+type txEntry struct {
+	time   time.Time
+	level  Level
+	msg    string
+	fields []Field
+}
+
+type tx struct {
+	l       *logger
+	entries []txEntry
+	sync.Mutex
+}
+
+func (l *logger) Begin() Tx {
+	return &tx{l: l}
+}
+
+// add keeps the entry if its level is enabled, formatting the message right away since the arguments may change.
+func (t *tx) add(level Level, fields []Field, msg func() string) {
+	if !noLog && atomic.LoadInt32(&t.l.level) >= int32(level) {
+		t.l.Lock()
+		now := t.l.now
+		t.l.Unlock()
+		e := txEntry{time: now(), level: level, msg: msg(), fields: append([]Field(nil), fields...)}
+		t.Lock()
+		t.entries = append(t.entries, e)
+		t.Unlock()
+	}
+	if level == LevelFatal {
+		_ = t.Commit()
+		t.l.exitIfFatal(level)
+	}
+}
+
+func (t *tx) Print(level Level, v ...any) {
+	t.add(level, nil, func() string { return fmt.Sprint(v...) })
+}
+
+func (t *tx) Println(level Level, v ...any) {
+	t.add(level, nil, func() string { return fmt.Sprintln(v...) })
+}
+
+func (t *tx) Printf(level Level, format string, v ...any) {
+	t.add(level, nil, func() string { return fmt.Sprintf(format, v...) })
+}
+
+func (t *tx) PrintFields(level Level, msg string, fields ...Field) {
+	t.add(level, fields, func() string { return msg })
+}
+
+func (t *tx) Commit() error {
+	t.Lock()
+	entries := t.entries
+	t.entries = nil
+	t.Unlock()
+	var first error
+	for _, e := range entries {
+		msg := e.msg
+		err := t.l.printTo(nil, e.time, e.level, e.fields, func(b *buffer) { *b = append(*b, msg...) })
+		t.l.handleError(err)
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+func (t *tx) Discard() {
+	t.Lock()
+	defer t.Unlock()
+	t.entries = nil
+}
@@ -0,0 +1,55 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTx(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelDebug, "", buf, 0)
+	clock := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	l.SetTimeFunc(func() time.Time { return clock })
+
+	tx := l.Begin()
+	tx.Println(LevelInfo, "Step", 1)
+	tx.Discard()
+	tx.Commit()
+	if buf.Len() != 0 {
+		t.Errorf("Discarded entries were written: %q", buf.String())
+	}
+
+	tx.Println(LevelInfo, "Step", 1)
+	clock = clock.Add(time.Second)
+	tx.Printf(LevelWarn, "Step %d", 2)
+	tx.Print(LevelTrace, "Disabled")
+	tx.PrintFields(LevelDebug, "Step 3", Int("n", 3))
+	l.Print(LevelInfo, "Direct")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "I/10:00:01 : Direct\nI/10:00:00 : Step 1\nW/10:00:01 : Step 2\nD/10:00:01 : Step 3 n=3\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+}
+
+func TestTxFatal(t *testing.T) {
+	codes := catchExit(t)
+	buf := new(bytes.Buffer)
+	l := New(LevelDebug, "", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	tx := l.Begin()
+	tx.Print(LevelInfo, "Before")
+	tx.Print(LevelFatal, "Failed")
+	expected := "I/10:00:00 : Before\nF/10:00:00 : Failed\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+	if len(*codes) != 1 || (*codes)[0] != 1 {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", []int{1}, *codes)
+	}
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"net"
+	"os"
+	"sync"
+)
+
+// UnixSocketWriter is an io.Writer sending every write as a datagram to a Unix socket, e.g. of a local log
+// aggregator. If sending fails, like after the aggregator restarted and recreated its socket, the writer
+// reconnects to the path and sends the datagram again, once.
+type UnixSocketWriter struct {
+	addr   *net.UnixAddr
+	conn   *net.UnixConn
+	closed bool
+	sync.Mutex
+}
+
+// NewUnixSocketWriter connects to the datagram socket at path.
+func NewUnixSocketWriter(path string) (*UnixSocketWriter, error) {
+	w := &UnixSocketWriter{addr: &net.UnixAddr{Name: path, Net: "unixgram"}}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// connect dials the socket. It must be called with the lock held.
+func (w *UnixSocketWriter) connect() error {
+	conn, err := net.DialUnix("unixgram", nil, w.addr)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+// Write sends p as a single datagram, reconnecting once if sending fails.
+func (w *UnixSocketWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.conn != nil {
+		if n, err := w.conn.Write(p); err == nil {
+			return n, nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	return w.conn.Write(p)
+}
+
+// Close closes the connection, subsequent writes fail with os.ErrClosed.
+func (w *UnixSocketWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
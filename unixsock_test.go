@@ -0,0 +1,62 @@
+//go:build !windows && !plan9 && !nolog
+
+package logger
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUnixSocketWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agg.sock")
+	listen := func() *net.UnixConn {
+		server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return server
+	}
+	read := func(server *net.UnixConn) string {
+		b := make([]byte, 4096)
+		_ = server.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := server.Read(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b[:n])
+	}
+	server := listen()
+
+	w, err := NewUnixSocketWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	l := New(LevelTrace, "", w, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	l.Println(LevelInfo, "first")
+	l.Println(LevelWarn, "multi\nline")
+	for _, expected := range []string{"I/10:00:00 : first\n", "W/10:00:00 : multi\nline\n"} {
+		if got := read(server); got != expected {
+			t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+		}
+	}
+
+	// The aggregator restarts, recreating its socket.
+	_ = server.Close()
+	_ = os.Remove(path)
+	server = listen()
+	defer server.Close()
+	l.Println(LevelInfo, "after restart")
+	if got := read(server); got != "I/10:00:00 : after restart\n" {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", "I/10:00:00 : after restart\n", got)
+	}
+
+	_ = w.Close()
+	if _, err := w.Write([]byte("closed")); err != os.ErrClosed {
+		t.Errorf("Pattern mismatch,\n\texpected: %v\n\tgot: %v", os.ErrClosed, err)
+	}
+}
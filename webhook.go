@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Webhook is a Hook posting a JSON payload to a URL for every entry at or above a minimum severity.
+// Payloads are posted by a background goroutine so logging is never blocked, and rate limited
+// to one per second by default to avoid storms. Entries are dropped while the limit is exceeded
+// or if too many payloads are waiting.
+type Webhook struct {
+	url      string
+	minLevel Level
+	client   *http.Client
+	interval time.Duration
+	last     time.Time
+	queue    chan []byte
+	done     chan struct{}
+	closed   bool
+	sync.Mutex
+}
+
+// webhookPayload is the JSON body posted for an entry.
+type webhookPayload struct {
+	Level   string         `json:"level"`
+	Time    time.Time      `json:"time"`
+	Message string         `json:"message"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// WebhookHook returns a Webhook posting entries with a Level at or more severe than minLevel to url.
+// Add it to a logger with ILogger.AddHook and Close it when done.
+func WebhookHook(url string, minLevel Level) *Webhook {
+	h := &Webhook{
+		url:      url,
+		minLevel: minLevel,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		interval: time.Second,
+		queue:    make(chan []byte, 64),
+		done:     make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// SetRateLimit sets the minimum interval between two posts, zero or negative disables rate limiting.
+func (h *Webhook) SetRateLimit(interval time.Duration) {
+	h.Lock()
+	defer h.Unlock()
+	h.interval = interval
+}
+
+// Fire queues a payload for the entry if its Level is severe enough and the rate limit allows it.
+func (h *Webhook) Fire(e *Entry) {
+	if e.Level > h.minLevel || e.Level <= LevelQuiet {
+		return
+	}
+	h.Lock()
+	defer h.Unlock()
+	now := time.Now()
+	if h.closed || h.interval > 0 && !h.last.IsZero() && now.Sub(h.last) < h.interval {
+		return
+	}
+	p := webhookPayload{Level: levelNames[e.Level], Time: e.Time, Message: e.Message}
+	if len(e.Fields) > 0 {
+		p.Fields = make(map[string]any, len(e.Fields))
+		for _, f := range e.Fields {
+			p.Fields[f.Key] = f.Value()
+			if f.kind == kindError && f.err != nil {
+				p.Fields[f.Key] = errorString(f.err)
+			}
+		}
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		// Values JSON can not encode, like channels or NaN, are sent as their text rather than losing the alert.
+		for k, v := range p.Fields {
+			p.Fields[k] = fmt.Sprint(v)
+		}
+		if b, err = json.Marshal(p); err != nil {
+			return
+		}
+	}
+	select {
+	case h.queue <- b:
+		h.last = now
+	default:
+	}
+}
+
+func (h *Webhook) run() {
+	defer close(h.done)
+	for b := range h.queue {
+		resp, err := h.client.Post(h.url, "application/json", bytes.NewReader(b))
+		if err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+	}
+}
+
+// Close stops accepting entries and waits until the queued payloads are posted.
+func (h *Webhook) Close() error {
+	h.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.Unlock()
+	<-h.done
+	return nil
+}
@@ -0,0 +1,106 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestWebhookHook(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		var p map[string]any
+		if err := json.Unmarshal(b, &p); err != nil {
+			t.Errorf("Invalid payload %s: %v", b, err)
+		}
+		mu.Lock()
+		payloads = append(payloads, p)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	hook := WebhookHook(server.URL, LevelError)
+	hook.SetRateLimit(0)
+	l := New(LevelTrace, "", io.Discard, 0)
+	l.AddHook(hook)
+	l.Println(LevelInfo, "Not alerted")
+	l.Println(LevelWarn, "Not alerted either")
+	l.PrintFields(LevelError, "Database down", String("db", "main"), Err(errors.New("refused")))
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l.Println(LevelError, "After close")
+
+	if len(payloads) != 1 {
+		t.Fatalf("Pattern mismatch,\n\texpected: 1 payload\n\tgot: %d payloads %v", len(payloads), payloads)
+	}
+	p := payloads[0]
+	fields, _ := p["fields"].(map[string]any)
+	if p["level"] != "error" || p["message"] != "Database down" || fields["db"] != "main" || fields["error"] != "refused" {
+		t.Errorf("Pattern mismatch,\n\texpected: error Database down db=main error=refused\n\tgot: %v", p)
+	}
+}
+
+func TestWebhookRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	hook := WebhookHook(server.URL, LevelError)
+	l := New(LevelTrace, "", io.Discard, 0)
+	l.AddHook(hook)
+	for i := 0; i < 10; i++ {
+		l.Println(LevelError, "Storm")
+	}
+	_ = hook.Close()
+	if count != 1 {
+		t.Errorf("Pattern mismatch,\n\texpected: 1 post\n\tgot: %d posts", count)
+	}
+}
+
+func TestWebhookFatal(t *testing.T) {
+	exit := CaptureExit()
+	defer exit.Restore()
+	var mu sync.Mutex
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&p)
+		mu.Lock()
+		payloads = append(payloads, p)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	hook := WebhookHook(server.URL, LevelError)
+	l := New(LevelTrace, "", io.Discard, 0)
+	l.AddHook(hook)
+	// The channel can not be encoded, it is sent as its text.
+	l.PrintFields(LevelFatal, "Crashed", Any("ch", make(chan int)))
+	if _, ok := exit.Code(); !ok {
+		t.Fatal("Fatal entry did not exit")
+	}
+	// The hook was closed before exiting, so the alert is already posted.
+	mu.Lock()
+	defer mu.Unlock()
+	if len(payloads) != 1 {
+		t.Fatalf("Pattern mismatch,\n\texpected: 1 payload\n\tgot: %d payloads %v", len(payloads), payloads)
+	}
+	fields, _ := payloads[0]["fields"].(map[string]any)
+	if payloads[0]["message"] != "Crashed" || fields["ch"] == nil {
+		t.Errorf("Pattern mismatch,\n\texpected: Crashed with ch\n\tgot: %v", payloads[0])
+	}
+}
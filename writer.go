@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+)
+
+// LevelWriter is an io.Writer logging every line written to it as an entry, see ILogger.Writer.
+// It is safe for concurrent use, but lines written concurrently in several parts may be mixed.
+type LevelWriter struct {
+	l     *logger
+	level Level
+	// tail holds the bytes written after the last newline, until the line is complete.
+	tail []byte
+	sync.Mutex
+}
+
+func (l *logger) Writer(level Level) *LevelWriter {
+	return &LevelWriter{l: l, level: level}
+}
+
+// Write logs every complete line of p, joined with the partial line left by previous writes.
+// The partial line at the end of p is kept until the next Write or Flush. It never fails.
+func (w *LevelWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	w.tail = append(w.tail, p...)
+	text := w.tail
+	for i := bytes.IndexByte(text, '\n'); i >= 0; i = bytes.IndexByte(text, '\n') {
+		w.print(text[:i])
+		text = text[i+1:]
+	}
+	w.tail = w.tail[:copy(w.tail, text)]
+	return len(p), nil
+}
+
+// Flush logs the partial line kept by Write, if any.
+func (w *LevelWriter) Flush() error {
+	w.Lock()
+	defer w.Unlock()
+	if len(w.tail) > 0 {
+		w.print(w.tail)
+		w.tail = w.tail[:0]
+	}
+	return nil
+}
+
+// print logs a line, without its "\r" if it ended with "\r\n". It must be called with the lock held.
+func (w *LevelWriter) print(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	w.l.Print(w.level, string(line))
+}
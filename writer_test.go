@@ -0,0 +1,35 @@
+//go:build !nolog
+
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLevelWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := New(LevelTrace, "", buf, 0)
+	l.SetTimeFunc(func() time.Time { return time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local) })
+	w := l.Writer(LevelWarn)
+	for _, part := range []string{"hel", "lo wo", "rld\nsecond", " line\r\n", "\n", "partial"} {
+		if n, err := w.Write([]byte(part)); err != nil || n != len(part) {
+			t.Fatalf("Write(%q) returned %d, %v", part, n, err)
+		}
+	}
+	expected := "W/10:00:00 : hello world\nW/10:00:00 : second line\nW/10:00:00 : \n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+	_ = w.Flush()
+	expected += "W/10:00:00 : partial\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+	// Nothing is left to flush.
+	_ = w.Flush()
+	if got := buf.String(); got != expected {
+		t.Errorf("Pattern mismatch,\n\texpected: %q\n\tgot: %q", expected, got)
+	}
+}